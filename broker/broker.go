@@ -0,0 +1,247 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+type (
+	// Broker is a registry of named Topics. Topics are created through the
+	// Broker with their Options, and can then be looked up by name from
+	// anywhere that has access to the Broker. Closing the Broker closes
+	// every Topic that it manages
+	Broker struct {
+		mu       sync.Mutex
+		topics   map[string]*registered
+		watchers map[id.ID]func(string, *registered)
+		closed   chan struct{}
+	}
+
+	// Description describes a Topic that is managed by a Broker
+	Description struct {
+		Name      string
+		Type      reflect.Type
+		Config    config.Config
+		Length    topic.Length
+		Consumers int
+	}
+
+	// registered is the Broker's record of one of its Topics. The Topic
+	// itself is stored untyped, so it must be asserted back to the
+	// Topic of its message Type when being looked up
+	registered struct {
+		topic    closer.Closer
+		msgType  reflect.Type
+		describe func() Description
+	}
+)
+
+// Error messages
+const (
+	ErrBrokerClosed  = "broker is closed"
+	ErrInvalidName   = "topic name must not be empty"
+	ErrTopicExists   = "topic already exists: %s"
+	ErrTopicNotFound = "topic not found: %s"
+	ErrTypeMismatch  = "topic %s carries messages of type %s, not %s"
+)
+
+// Make instantiates a new Broker
+func Make() *Broker {
+	return &Broker{
+		topics:   map[string]*registered{},
+		watchers: map[id.ID]func(string, *registered){},
+		closed:   make(chan struct{}),
+	}
+}
+
+// Topic returns the named Topic, creating it with the specified Options if it
+// doesn't already exist. The Options of an existing Topic are left as they
+// were, and an error is returned if its messages aren't of type Msg
+func Topic[Msg any](
+	b *Broker, name string, o ...config.Option,
+) (topic.Topic[Msg], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r, ok, err := b.lookup(name); err != nil {
+		return nil, err
+	} else if ok {
+		return typed[Msg](name, r)
+	}
+	return create[Msg](b, name, o...)
+}
+
+// Create creates a new Topic with the specified name and Options, returning
+// an error if a Topic with that name already exists
+func Create[Msg any](
+	b *Broker, name string, o ...config.Option,
+) (topic.Topic[Msg], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok, err := b.lookup(name); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, fmt.Errorf(ErrTopicExists, name)
+	}
+	return create[Msg](b, name, o...)
+}
+
+// Get returns the named Topic, returning an error if it doesn't exist or if
+// its messages aren't of type Msg
+func Get[Msg any](b *Broker, name string) (topic.Topic[Msg], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r, ok, err := b.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf(ErrTopicNotFound, name)
+	}
+	return typed[Msg](name, r)
+}
+
+func create[Msg any](
+	b *Broker, name string, o ...config.Option,
+) (topic.Topic[Msg], error) {
+	if name == "" {
+		return nil, errors.New(ErrInvalidName)
+	}
+	t, err := internal.Open[Msg](o...)
+	if err != nil {
+		return nil, err
+	}
+	msgType := reflect.TypeFor[Msg]()
+	r := &registered{
+		topic:   t,
+		msgType: msgType,
+		describe: func() Description {
+			return Description{
+				Name:      name,
+				Type:      msgType,
+				Config:    *t.Config,
+				Length:    t.Length(),
+				Consumers: t.Consumers(),
+			}
+		},
+	}
+	b.topics[name] = r
+	for _, w := range b.watchers {
+		w(name, r)
+	}
+	return t, nil
+}
+
+func typed[Msg any](name string, r *registered) (topic.Topic[Msg], error) {
+	if res, ok := r.topic.(topic.Topic[Msg]); ok {
+		return res, nil
+	}
+	return nil, fmt.Errorf(ErrTypeMismatch,
+		name, r.msgType, reflect.TypeFor[Msg](),
+	)
+}
+
+// lookup returns the named Topic's record. Topics that were closed directly,
+// rather than through the Broker, are forgotten as they're encountered
+func (b *Broker) lookup(name string) (*registered, bool, error) {
+	if err := b.checkOpen(); err != nil {
+		return nil, false, err
+	}
+	r, ok := b.topics[name]
+	if ok && closer.IsClosed(r.topic) {
+		delete(b.topics, name)
+		return nil, false, nil
+	}
+	return r, ok, nil
+}
+
+func (b *Broker) checkOpen() error {
+	if closer.IsClosed(b) {
+		return errors.New(ErrBrokerClosed)
+	}
+	return nil
+}
+
+func (b *Broker) unwatch(i id.ID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.watchers, i)
+}
+
+// Names returns the sorted names of the Topics managed by the Broker
+func (b *Broker) Names() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := make([]string, 0, len(b.topics))
+	for name, r := range b.topics {
+		if closer.IsClosed(r.topic) {
+			delete(b.topics, name)
+			continue
+		}
+		res = append(res, name)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Describe returns a Description of the named Topic
+func (b *Broker) Describe(name string) (Description, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r, ok, err := b.lookup(name)
+	if err != nil {
+		return Description{}, err
+	}
+	if !ok {
+		return Description{}, fmt.Errorf(ErrTopicNotFound, name)
+	}
+	return r.describe(), nil
+}
+
+// CloseTopic closes the named Topic and removes it from the Broker
+func (b *Broker) CloseTopic(name string) error {
+	b.mu.Lock()
+	r, ok, err := b.lookup(name)
+	if ok {
+		delete(b.topics, name)
+	}
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf(ErrTopicNotFound, name)
+	}
+	r.topic.Close()
+	return nil
+}
+
+// Close closes the Broker and every Topic that it manages
+func (b *Broker) Close() {
+	b.mu.Lock()
+	if closer.IsClosed(b) {
+		b.mu.Unlock()
+		return
+	}
+	close(b.closed)
+	topics := b.topics
+	b.topics = map[string]*registered{}
+	b.mu.Unlock()
+	for _, r := range topics {
+		r.topic.Close()
+	}
+}
+
+// IsClosed returns a channel that is closed when the Broker is closed
+func (b *Broker) IsClosed() <-chan struct{} {
+	return b.closed
+}
@@ -0,0 +1,151 @@
+package broker_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/broker"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrokerTopic(t *testing.T) {
+	as := assert.New(t)
+	b := essentials.NewBroker()
+
+	orders, err := broker.Topic[string](b, "orders", config.Permanent)
+	as.Nil(err)
+	p := orders.NewProducer()
+	p.Send() <- "first order"
+	p.Close()
+
+	same, err := broker.Topic[string](b, "orders")
+	as.Nil(err)
+	as.Equal(orders, same)
+
+	got, err := broker.Get[string](b, "orders")
+	as.Nil(err)
+	c := got.NewConsumer()
+	as.Equal("first order", message.MustReceive[string](c))
+	c.Close()
+
+	b.Close()
+	as.True(closer.IsClosed(orders))
+}
+
+func TestBrokerCreate(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+
+	_, err := broker.Create[int](b, "numbers")
+	as.Nil(err)
+	_, err = broker.Create[int](b, "numbers")
+	as.EqualError(err, fmt.Sprintf(broker.ErrTopicExists, "numbers"))
+
+	_, err = broker.Create[int](b, "")
+	as.EqualError(err, broker.ErrInvalidName)
+
+	_, err = broker.Create[int](b, "invalid",
+		config.Permanent, config.Permanent,
+	)
+	as.EqualError(err, config.ErrRetentionPolicyAlreadySet)
+	as.Equal([]string{"numbers"}, b.Names())
+	b.Close()
+}
+
+func TestBrokerTypeMismatch(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+
+	_, err := broker.Create[int](b, "numbers")
+	as.Nil(err)
+
+	_, err = broker.Get[string](b, "numbers")
+	as.EqualError(err,
+		fmt.Sprintf(broker.ErrTypeMismatch, "numbers", "int", "string"),
+	)
+
+	_, err = broker.Topic[any](b, "numbers")
+	as.EqualError(err,
+		fmt.Sprintf(broker.ErrTypeMismatch, "numbers", "int", "interface {}"),
+	)
+
+	_, err = broker.Get[int](b, "missing")
+	as.EqualError(err, fmt.Sprintf(broker.ErrTopicNotFound, "missing"))
+	b.Close()
+}
+
+func TestBrokerDescribe(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+
+	top, err := broker.Create[string](b, "events", config.Permanent)
+	as.Nil(err)
+	l := top.NewProducer()
+	l.Send() <- "one"
+	l.Send() <- "two"
+	l.Close()
+
+	c1 := top.NewConsumer()
+	c2 := top.NewConsumer(topic.Group("group"))
+	c3 := top.NewConsumer(topic.Group("group"))
+
+	d, err := b.Describe("events")
+	as.Nil(err)
+	as.Equal("events", d.Name)
+	as.Equal(reflect.TypeFor[string](), d.Type)
+	as.Equal(topic.Length(2), d.Length)
+	as.Equal(3, d.Consumers)
+	as.NotNil(d.Config.RetentionPolicy)
+	as.Equal(uint16(config.DefaultSegmentIncrement), d.Config.SegmentIncrement)
+
+	c1.Close()
+	c2.Close()
+	c3.Close()
+	d, _ = b.Describe("events")
+	as.Equal(0, d.Consumers)
+
+	_, err = b.Describe("missing")
+	as.EqualError(err, fmt.Sprintf(broker.ErrTopicNotFound, "missing"))
+	b.Close()
+}
+
+func TestBrokerCloseTopic(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+
+	first, _ := broker.Create[string](b, "first")
+	second, _ := broker.Create[string](b, "second")
+	third, _ := broker.Create[string](b, "third")
+	as.Equal([]string{"first", "second", "third"}, b.Names())
+
+	as.Nil(b.CloseTopic("second"))
+	as.True(closer.IsClosed(second))
+	as.EqualError(b.CloseTopic("second"),
+		fmt.Sprintf(broker.ErrTopicNotFound, "second"),
+	)
+
+	// Topics closed directly are forgotten by the Broker
+	third.Close()
+	as.Equal([]string{"first"}, b.Names())
+
+	// and their names can be reused
+	replaced, err := broker.Topic[int](b, "third")
+	as.Nil(err)
+	as.NotNil(replaced)
+
+	b.Close()
+	as.True(closer.IsClosed(first))
+	as.True(closer.IsClosed(replaced))
+	as.Empty(b.Names())
+
+	_, err = broker.Topic[string](b, "first")
+	as.EqualError(err, broker.ErrBrokerClosed)
+	as.EqualError(b.CloseTopic("first"), broker.ErrBrokerClosed)
+	b.Close()
+}
@@ -0,0 +1,34 @@
+package broker
+
+import (
+	"path"
+	"regexp"
+)
+
+// Matcher reports whether a subscription includes the Topic with the
+// specified name. Matchers are called while the Broker is locked, so they
+// must not call back into the Broker
+type Matcher func(name string) bool
+
+// Glob returns a Matcher for names that match the specified shell pattern,
+// such as "orders.*". The pattern syntax is that of path.Match, and an error
+// is returned if the pattern is malformed
+func Glob(pattern string) (Matcher, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	return func(name string) bool {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}, nil
+}
+
+// Regexp returns a Matcher for names that match the specified regular
+// expression. Unless anchored, the expression may match any part of a name
+func Regexp(expr string) (Matcher, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
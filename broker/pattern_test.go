@@ -0,0 +1,36 @@
+package broker_test
+
+import (
+	"testing"
+
+	"github.com/caravan/essentials/broker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlob(t *testing.T) {
+	as := assert.New(t)
+	m, err := broker.Glob("orders.*")
+	as.Nil(err)
+	as.True(m("orders.eu"))
+	as.True(m("orders."))
+	as.False(m("orders"))
+	as.False(m("returns.eu"))
+
+	_, err = broker.Glob("orders.[")
+	as.NotNil(err)
+}
+
+func TestRegexp(t *testing.T) {
+	as := assert.New(t)
+	m, err := broker.Regexp(`^events\.(login|logout)$`)
+	as.Nil(err)
+	as.True(m("events.login"))
+	as.False(m("events.signup"))
+
+	m, err = broker.Regexp(`audit`)
+	as.Nil(err)
+	as.True(m("events.audit.trail"))
+
+	_, err = broker.Regexp(`(`)
+	as.NotNil(err)
+}
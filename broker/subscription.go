@@ -0,0 +1,190 @@
+package broker
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+)
+
+type (
+	// Subscription receives the messages of every Topic in a Broker whose
+	// name is included by a Matcher, including Topics that are created
+	// after the Subscription. Messages from each Topic are received in
+	// order, but the streams of different Topics are interleaved
+	Subscription[Msg any] interface {
+		message.ClosingReceiver[Sourced[Msg]]
+		topic.Identified
+
+		// Topics returns the sorted names of the Topics that the
+		// Subscription is currently attached to
+		Topics() []string
+	}
+
+	// Sourced pairs a message with the name of the Topic it was received
+	// from
+	Sourced[Msg any] struct {
+		Topic   string
+		Message Msg
+	}
+
+	subscription[Msg any] struct {
+		sync.Mutex
+		id        id.ID
+		broker    *Broker
+		options   []topic.ConsumerOption
+		consumers map[string]topic.Consumer[Msg]
+		channel   chan Sourced[Msg]
+		closed    chan struct{}
+		forwards  sync.WaitGroup
+	}
+)
+
+// Subscribe returns a Subscription to the messages of every Topic whose name
+// is included by the Matcher. A Consumer is attached to each Topic using the
+// specified ConsumerOptions. Topics whose messages aren't of type Msg are
+// skipped. The Subscription is closed once the Broker is closed and every
+// Topic it was attached to has been drained
+func Subscribe[Msg any](
+	b *Broker, m Matcher, o ...topic.ConsumerOption,
+) (Subscription[Msg], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	res := &subscription[Msg]{
+		id:        id.New(),
+		broker:    b,
+		options:   o,
+		consumers: map[string]topic.Consumer[Msg]{},
+		channel:   make(chan Sourced[Msg]),
+		closed:    make(chan struct{}),
+	}
+	attach := func(name string, r *registered) {
+		if !m(name) {
+			return
+		}
+		if t, err := typed[Msg](name, r); err == nil {
+			res.attach(name, t)
+		}
+	}
+	for name, r := range b.topics {
+		if !closer.IsClosed(r.topic) {
+			attach(name, r)
+		}
+	}
+	b.watchers[res.id] = attach
+	go res.stopWith(b)
+	return res, nil
+}
+
+func (s *subscription[_]) ID() id.ID {
+	return s.id
+}
+
+func (s *subscription[Msg]) Receive() <-chan Sourced[Msg] {
+	return s.channel
+}
+
+func (s *subscription[_]) Topics() []string {
+	s.Lock()
+	defer s.Unlock()
+	res := make([]string, 0, len(s.consumers))
+	for name := range s.consumers {
+		res = append(res, name)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Close detaches the Subscription from the Broker and from each of its
+// Topics, after which its channel is closed
+func (s *subscription[Msg]) Close() {
+	s.Lock()
+	select {
+	case <-s.closed:
+		s.Unlock()
+		return
+	default:
+		close(s.closed)
+	}
+	consumers := s.consumers
+	s.consumers = map[string]topic.Consumer[Msg]{}
+	s.Unlock()
+
+	s.broker.unwatch(s.id)
+	for _, c := range consumers {
+		c.Close()
+	}
+}
+
+func (s *subscription[_]) IsClosed() <-chan struct{} {
+	return s.closed
+}
+
+// attach starts forwarding the messages of a Topic to the Subscription's
+// channel. It's only called while the Broker is locked, so a Topic can't be
+// produced to before its Consumer is attached
+func (s *subscription[Msg]) attach(name string, t topic.Topic[Msg]) {
+	s.Lock()
+	defer s.Unlock()
+	select {
+	case <-s.closed:
+		return
+	default:
+	}
+	c := t.NewConsumer(s.options...)
+	s.consumers[name] = c
+	s.forwards.Add(1)
+	go s.forward(name, c)
+}
+
+func (s *subscription[Msg]) detach(name string, c topic.Consumer[Msg]) {
+	s.Lock()
+	defer s.Unlock()
+	if s.consumers[name] == c {
+		delete(s.consumers, name)
+	}
+}
+
+// forward delivers a Topic's messages until either the Subscription is
+// closed or the Topic is closed and drained
+func (s *subscription[Msg]) forward(name string, c topic.Consumer[Msg]) {
+	defer s.forwards.Done()
+	defer s.detach(name, c)
+	for {
+		select {
+		case <-s.closed:
+			return
+		case msg, ok := <-c.Receive():
+			if !ok {
+				c.Close()
+				return
+			}
+			select {
+			case <-s.closed:
+				return
+			case s.channel <- Sourced[Msg]{Topic: name, Message: msg}:
+			}
+		}
+	}
+}
+
+// stopWith closes the Subscription's channel once it's closed, or once the
+// Broker is closed and every Topic the Subscription was attached to has been
+// drained. No Topics can be attached after either of those happen
+func (s *subscription[_]) stopWith(b *Broker) {
+	select {
+	case <-s.closed:
+	case <-b.IsClosed():
+		b.unwatch(s.id)
+	}
+	s.forwards.Wait()
+	s.Close()
+	close(s.channel)
+}
@@ -0,0 +1,121 @@
+package broker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/broker"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustGlob(t *testing.T, pattern string) broker.Matcher {
+	m, err := broker.Glob(pattern)
+	assert.Nil(t, err)
+	return m
+}
+
+func put[Msg any](t *testing.T, b *broker.Broker, name string, msg Msg) {
+	top, err := broker.Topic[Msg](b, name)
+	assert.Nil(t, err)
+	p := top.NewProducer()
+	p.Send() <- msg
+	p.Close()
+}
+
+func TestSubscribe(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	put(t, b, "events.login", "alice")
+	put(t, b, "orders.new", "ignored")
+	put(t, b, "events.count", 42) // wrong message type
+
+	s, err := broker.Subscribe[string](b, mustGlob(t, "events.*"))
+	as.Nil(err)
+	as.Equal([]string{"events.login"}, s.Topics())
+
+	msg := message.MustReceive[broker.Sourced[string]](s)
+	as.Equal(broker.Sourced[string]{
+		Topic:   "events.login",
+		Message: "alice",
+	}, msg)
+
+	// Topics created later are attached automatically
+	put(t, b, "events.logout", "bob")
+	as.Equal([]string{"events.login", "events.logout"}, s.Topics())
+	msg = message.MustReceive[broker.Sourced[string]](s)
+	as.Equal("events.logout", msg.Topic)
+	as.Equal("bob", msg.Message)
+
+	put(t, b, "events.login", "carol")
+	msg = message.MustReceive[broker.Sourced[string]](s)
+	as.Equal("events.login", msg.Topic)
+	as.Equal("carol", msg.Message)
+
+	_, ok := message.Poll[broker.Sourced[string]](s, 10*time.Millisecond)
+	as.False(ok)
+
+	s.Close()
+	_, ok = <-s.Receive()
+	as.False(ok)
+	as.Empty(s.Topics())
+	b.Close()
+}
+
+func TestSubscribeConsumerOptions(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	put(t, b, "a", 1)
+
+	s, err := broker.Subscribe[int](b, mustGlob(t, "*"),
+		topic.StartAt(topic.Latest),
+	)
+	as.Nil(err)
+	_, ok := message.Poll[broker.Sourced[int]](s, 10*time.Millisecond)
+	as.False(ok)
+
+	put(t, b, "a", 2)
+	as.Equal(2, message.MustReceive[broker.Sourced[int]](s).Message)
+	s.Close()
+	b.Close()
+}
+
+func TestSubscribeTopicClosed(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	s, err := broker.Subscribe[string](b, mustGlob(t, "jobs.*"))
+	as.Nil(err)
+
+	put(t, b, "jobs.first", "value")
+	as.Nil(b.CloseTopic("jobs.first"))
+	as.Equal("value", message.MustReceive[broker.Sourced[string]](s).Message)
+	as.Eventually(func() bool {
+		return len(s.Topics()) == 0
+	}, time.Second, time.Millisecond)
+	as.False(closer.IsClosed(s))
+	s.Close()
+	b.Close()
+}
+
+func TestSubscribeBrokerClosed(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	s, err := broker.Subscribe[string](b, mustGlob(t, "*"))
+	as.Nil(err)
+	put(t, b, "first", "one")
+	put(t, b, "second", "two")
+	b.Close()
+
+	// retained messages are drained before the Subscription closes
+	seen := map[string]string{}
+	for msg := range s.Receive() {
+		seen[msg.Topic] = msg.Message
+	}
+	as.Equal(map[string]string{"first": "one", "second": "two"}, seen)
+	as.True(closer.IsClosed(s))
+
+	_, err = broker.Subscribe[string](b, mustGlob(t, "*"))
+	as.EqualError(err, broker.ErrBrokerClosed)
+}
@@ -1,6 +1,7 @@
 package essentials
 
 import (
+	"github.com/caravan/essentials/broker"
 	"github.com/caravan/essentials/topic"
 	"github.com/caravan/essentials/topic/config"
 
@@ -11,3 +12,66 @@ import (
 func NewTopic[Msg any](o ...config.Option) topic.Topic[Msg] {
 	return internal.Make[Msg](o...)
 }
+
+// OpenTopic instantiates a new Topic, given the specified Options. Unlike
+// NewTopic, an error is returned if the Options are invalid or if the Topic's
+// storage can't be read, such as when a persistent segment is corrupt
+func OpenTopic[Msg any](o ...config.Option) (topic.Topic[Msg], error) {
+	t, err := internal.Open[Msg](o...)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewPartitionedTopic instantiates a new PartitionedTopic with the specified
+// number of partitions, given the specified Options. The Options apply to each
+// partition separately, so bounds such as MaxMessages and MaxBytes limit every
+// partition to that amount rather than the PartitionedTopic as a whole
+func NewPartitionedTopic[Msg any](
+	partitions int, o ...config.Option,
+) topic.PartitionedTopic[Msg] {
+	return internal.MakePartitioned[Msg](partitions, o...)
+}
+
+// OpenPartitionedTopic instantiates a new PartitionedTopic with the specified
+// number of partitions, given the specified Options. Unlike
+// NewPartitionedTopic, an error is returned if the Options are invalid or if
+// a partition's storage can't be read
+func OpenPartitionedTopic[Msg any](
+	partitions int, o ...config.Option,
+) (topic.PartitionedTopic[Msg], error) {
+	t, err := internal.OpenPartitioned[Msg](partitions, o...)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewPriorityTopic instantiates a new PriorityTopic with the specified number
+// of priority levels, given the specified Options
+func NewPriorityTopic[Msg any](
+	levels int, o ...config.Option,
+) topic.PriorityTopic[Msg] {
+	return internal.MakePriority[Msg](levels, o...)
+}
+
+// OpenPriorityTopic instantiates a new PriorityTopic with the specified number
+// of priority levels, given the specified Options. Unlike NewPriorityTopic, an
+// error is returned if the Options are invalid or if a level's storage can't
+// be read
+func OpenPriorityTopic[Msg any](
+	levels int, o ...config.Option,
+) (topic.PriorityTopic[Msg], error) {
+	t, err := internal.OpenPriority[Msg](levels, o...)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewBroker instantiates a new Broker, for creating and looking up Topics by
+// name
+func NewBroker() *broker.Broker {
+	return broker.Make()
+}
@@ -1,6 +1,6 @@
 module github.com/caravan/essentials
 
-go 1.19
+go 1.23
 
 require (
 	github.com/google/uuid v1.3.0
@@ -4,12 +4,12 @@ import "time"
 
 // Timeout returns a receive-only channel that will be closed after the
 // specified Duration. The value of a structure like this over a direct call to
-// time.Sleep is that a channel can participate in a select
+// time.Sleep is that a channel can participate in a select. Routines that wait
+// repeatedly should reuse a Timer instead
 func Timeout(d time.Duration) <-chan struct{} {
 	ch := make(chan struct{})
-	go func() {
-		time.Sleep(d)
+	time.AfterFunc(d, func() {
 		close(ch)
-	}()
+	})
 	return ch
 }
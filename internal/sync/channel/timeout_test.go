@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/caravan/essentials/internal/sync/channel"
+	"github.com/caravan/essentials/topic/clock"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestTimeout(t *testing.T) {
@@ -20,3 +22,46 @@ func TestTimeout(t *testing.T) {
 		t.Errorf("Timeout should have happened first")
 	}
 }
+
+func TestTimer(t *testing.T) {
+	as := assert.New(t)
+
+	timer := channel.MakeTimer(clock.System.NewTimer)
+	select {
+	case <-timer.Reset(time.Hour):
+		as.Fail("Timer should not have expired")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// a Reset discards the previous expiration
+	timer.Reset(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case <-timer.Reset(time.Hour):
+		as.Fail("Timer should have been reset")
+	default:
+	}
+
+	select {
+	case <-timer.Reset(time.Millisecond):
+	case <-time.After(time.Second):
+		as.Fail("Timer should have expired")
+	}
+	timer.Stop()
+}
+
+func BenchmarkTimeout(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		<-channel.Timeout(0)
+	}
+}
+
+func BenchmarkTimer(b *testing.B) {
+	b.ReportAllocs()
+	timer := channel.MakeTimer(clock.System.NewTimer)
+	defer timer.Stop()
+	for i := 0; i < b.N; i++ {
+		<-timer.Reset(0)
+	}
+}
@@ -0,0 +1,47 @@
+package channel
+
+import "time"
+
+type (
+	// Timer is a reusable timeout. Unlike Timeout, resetting a Timer neither
+	// starts a routine nor allocates a new channel, which makes it suitable
+	// for the wait loops of long-lived routines that are mostly idle
+	Timer struct {
+		timer Expirer
+	}
+
+	// Expirer is the underlying timer that drives a Timer, such as one
+	// returned by a Clock
+	Expirer interface {
+		C() <-chan time.Time
+		Reset(time.Duration)
+		Stop()
+	}
+)
+
+// MakeTimer returns a new Timer, driven by an Expirer that the specified
+// function creates, that is stopped until it is first Reset
+func MakeTimer[T Expirer](newTimer func(time.Duration) T) *Timer {
+	t := newTimer(time.Hour)
+	t.Stop()
+	return &Timer{timer: t}
+}
+
+// Reset restarts the Timer so that it expires after the specified Duration,
+// and returns a channel that can participate in a select. An expiration from
+// before the Reset that hasn't been received is discarded, so that it isn't
+// mistaken for the new one
+func (t *Timer) Reset(d time.Duration) <-chan time.Time {
+	t.timer.Stop()
+	select {
+	case <-t.timer.C():
+	default:
+	}
+	t.timer.Reset(d)
+	return t.timer.C()
+}
+
+// Stop prevents the Timer from expiring
+func (t *Timer) Stop() {
+	t.timer.Stop()
+}
@@ -0,0 +1,437 @@
+package topic
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/internal/sync/channel"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/retention"
+)
+
+type (
+	// ackConsumer is a Consumer that tracks its delivered messages until
+	// they are acknowledged, redelivering those that aren't. If it's shared
+	// by the members of a consumer group, its roster is set, and messages
+	// are delivered to those members rather than to its own channel
+	ackConsumer[Msg any] struct {
+		*cursor[Msg]
+		sync.Mutex
+		id         id.ID
+		channel    chan topic.Delivery[Msg]
+		seeks      chan topic.Position
+		wake       *channel.ReadyWait
+		inflight   map[retention.Offset]*inflight[Msg]
+		visibility time.Duration
+		redelivery backoff.Generator
+		deadLetter topic.Producer[topic.DeadLetter[Msg]]
+		roster     *roster[topic.Delivery[Msg]]
+	}
+
+	// inflight tracks a message that has been delivered, but not yet
+	// acknowledged
+	inflight[Msg any] struct {
+		offset    retention.Offset
+		msg       Msg
+		expiresAt time.Time
+		attempts  int
+		due       time.Time
+		lastErr   error
+		retry     backoff.Next
+		receiver  id.ID
+		acked     bool
+		nacked    bool
+	}
+
+	// delivery is a single delivery attempt of an inflight message. Because
+	// a receiver may acknowledge a delivery before the consumer has recorded
+	// it as sent, an early negative acknowledgement is held until then
+	delivery[Msg any] struct {
+		consumer *ackConsumer[Msg]
+		inflight *inflight[Msg]
+		receiver id.ID
+		attempt  int
+		sent     bool
+		nacked   bool
+		nackErr  error
+	}
+)
+
+func makeAckConsumer[Msg any](
+	c *cursor[Msg], b backoff.Generator, cfg *topic.ConsumerConfig,
+	r *roster[topic.Delivery[Msg]],
+) *ackConsumer[Msg] {
+	res := &ackConsumer[Msg]{
+		cursor:     c,
+		id:         c.id,
+		channel:    make(chan topic.Delivery[Msg]),
+		seeks:      make(chan topic.Position),
+		wake:       channel.MakeReadyWait(),
+		inflight:   map[retention.Offset]*inflight[Msg]{},
+		visibility: cfg.VisibilityTimeout,
+		redelivery: cfg.Redelivery,
+		roster:     r,
+	}
+	if dl := c.topic.deadLetter; dl != nil {
+		res.deadLetter = dl.NewProducer()
+	}
+	c.floor = res.floor
+	go res.start(b)
+	return res
+}
+
+func (c *ackConsumer[_]) ID() id.ID {
+	return c.id
+}
+
+func (c *ackConsumer[Msg]) Receive() <-chan topic.Delivery[Msg] {
+	return c.channel
+}
+
+// Seek repositions the Consumer's read position. Messages that are already in
+// flight will continue to be redelivered until they're acknowledged
+func (c *ackConsumer[_]) Seek(p topic.Position) {
+	c.reposition(p, nil)
+}
+
+// reposition applies a Seek, unless the specified channel or the Consumer is
+// closed first
+func (c *ackConsumer[_]) reposition(p topic.Position, closed <-chan struct{}) {
+	p = resolve(c.topic, p)
+	select {
+	case <-closed:
+	case <-c.IsClosed():
+	case c.seeks <- p:
+	}
+}
+
+func (c *ackConsumer[Msg]) start(b backoff.Generator) {
+	drained := false
+	defer func() {
+		if c.deadLetter != nil {
+			c.deadLetter.Close()
+		}
+		close(c.channel)
+		if c.roster != nil {
+			c.roster.stop()
+			if drained {
+				c.roster.closeMembers()
+			}
+		}
+	}()
+	timer := channel.MakeTimer(c.topic.Clock.NewTimer)
+	defer timer.Stop()
+	next := b()
+	for !closer.IsClosed(c) {
+		if d, ok := c.nextDelivery(); ok {
+			wait := timer.Reset(c.nextWait(next()))
+			if c.offer(d, wait) {
+				next = b()
+			}
+			continue
+		}
+
+		if c.drained() && !c.hasInflight() {
+			// the Topic is closed and has been drained
+			drained = true
+			c.Close()
+			return
+		}
+
+		select {
+		case <-c.IsClosed():
+			return
+		case p := <-c.seeks:
+			c.seek(p)
+			next = b()
+		case <-c.changed():
+			c.roster.release()
+		case <-c.closing():
+		case <-timer.Reset(c.nextWait(next())):
+		case <-c.wake.Wait():
+		case <-c.ready.Wait():
+		}
+	}
+}
+
+// offer makes a Delivery to the Consumer's channel, or to whichever member of
+// its group is first ready to receive it. It returns whether the Delivery was
+// made or the Consumer was repositioned, rather than the wait having elapsed
+// to allow retention policies and redelivery deadlines to kick in
+func (c *ackConsumer[Msg]) offer(
+	d *delivery[Msg], wait <-chan time.Time,
+) bool {
+	if c.roster == nil {
+		select {
+		case <-c.IsClosed():
+			return false
+		case p := <-c.seeks:
+			c.seek(p)
+			return true
+		case <-wait:
+			return false
+		case c.channel <- d:
+			c.delivered(d)
+			return true
+		}
+	}
+
+	members := c.roster.channels()
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.IsClosed())},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.seeks)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.changed())},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(wait)},
+	}
+	deliveries := make([]*delivery[Msg], 0, len(members))
+	cases = appendSends(cases, members,
+		func(m *member[topic.Delivery[Msg]]) topic.Delivery[Msg] {
+			res := *d
+			res.receiver = m.id
+			deliveries = append(deliveries, &res)
+			return &res
+		},
+	)
+	switch chosen, recv, _ := reflect.Select(cases); chosen {
+	case 0, 2, 3:
+		return false
+	case 1:
+		c.seek(recv.Interface().(topic.Position))
+		return true
+	default:
+		c.delivered(deliveries[chosen-4])
+		return true
+	}
+}
+
+// changed returns a channel that's closed when the membership of the
+// Consumer's group changes, or nil if it isn't shared by a group
+func (c *ackConsumer[_]) changed() <-chan struct{} {
+	if c.roster == nil {
+		return nil
+	}
+	return c.roster.changed.Wait()
+}
+
+// nextDelivery returns a Delivery for the lowest overdue inflight message, if
+// there is one, otherwise for the message at the cursor's head. Overdue
+// messages that have exhausted their delivery attempts are dead-lettered, and
+// those that have expired are dropped
+func (c *ackConsumer[Msg]) nextDelivery() (*delivery[Msg], bool) {
+	for {
+		due, ok := c.nextDue()
+		if !ok {
+			break
+		}
+		if !c.exhausted(due) {
+			return c.makeDelivery(due), true
+		}
+		c.sendDeadLetter(due)
+	}
+	if e, ok := c.entry(); ok {
+		return c.makeDelivery(&inflight[Msg]{
+			offset:    c.position(),
+			msg:       e.msg,
+			expiresAt: c.topic.log.expiresAt(e),
+			retry:     c.redelivery(),
+		}), true
+	}
+	return nil, false
+}
+
+func (c *ackConsumer[Msg]) nextDue() (*inflight[Msg], bool) {
+	c.Lock()
+	defer c.Unlock()
+	var due *inflight[Msg]
+	now := c.topic.Clock.Now()
+	for o, i := range c.inflight {
+		if i.due.After(now) {
+			continue
+		}
+		if i.isExpired(now) {
+			// a stale message is never redelivered
+			delete(c.inflight, o)
+			c.topic.log.countExpired()
+			continue
+		}
+		if due == nil || i.offset < due.offset {
+			due = i
+		}
+	}
+	if due == nil {
+		return nil, false
+	}
+	if !due.nacked {
+		due.lastErr = errors.New(topic.ErrVisibilityTimeout)
+	}
+	return due, true
+}
+
+// isExpired returns whether the message's time-to-live has passed as of the
+// specified Time
+func (i *inflight[_]) isExpired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !i.expiresAt.After(now)
+}
+
+func (c *ackConsumer[Msg]) exhausted(i *inflight[Msg]) bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.deadLetter != nil && i.attempts >= c.topic.MaxDeliveryAttempts
+}
+
+// sendDeadLetter moves an inflight message to the dead-letter Topic
+func (c *ackConsumer[Msg]) sendDeadLetter(i *inflight[Msg]) {
+	c.Lock()
+	dl := topic.DeadLetter[Msg]{
+		Message:    i.msg,
+		Offset:     i.offset,
+		Attempts:   i.attempts,
+		ConsumerID: i.receiver,
+	}
+	if i.lastErr != nil {
+		dl.LastError = i.lastErr.Error()
+	}
+	delete(c.inflight, i.offset)
+	c.Unlock()
+
+	if !message.Send[topic.DeadLetter[Msg]](c.deadLetter, dl) {
+		reportError(fmt.Errorf(ErrDeadLetterNotSent, i.offset, c.id))
+	}
+}
+
+func (c *ackConsumer[Msg]) makeDelivery(i *inflight[Msg]) *delivery[Msg] {
+	c.Lock()
+	defer c.Unlock()
+	return &delivery[Msg]{
+		consumer: c,
+		inflight: i,
+		receiver: c.id,
+		attempt:  i.attempts + 1,
+	}
+}
+
+func (c *ackConsumer[Msg]) delivered(d *delivery[Msg]) {
+	c.Lock()
+	defer c.Unlock()
+	d.sent = true
+	i := d.inflight
+	i.attempts = d.attempt
+	i.receiver = d.receiver
+	if d.attempt == 1 {
+		c.advance()
+	}
+	if i.acked {
+		return
+	}
+	c.inflight[i.offset] = i
+	i.nacked = false
+	if d.nacked {
+		c.retry(i, d.nackErr)
+		return
+	}
+	i.due = c.topic.Clock.Now().Add(c.visibility)
+}
+
+// nextWait returns the specified Duration or the time remaining until the
+// next inflight message is due, whichever is shorter
+func (c *ackConsumer[_]) nextWait(d time.Duration) time.Duration {
+	c.Lock()
+	defer c.Unlock()
+	now := c.topic.Clock.Now()
+	for _, i := range c.inflight {
+		if w := i.due.Sub(now); w < d {
+			d = w
+		}
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+func (c *ackConsumer[_]) hasInflight() bool {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.inflight) != 0
+}
+
+// floor returns the lowest unacknowledged Offset, so that retention policies
+// don't discard messages that may still need to be redelivered
+func (c *ackConsumer[_]) floor() (retention.Offset, bool) {
+	c.Lock()
+	defer c.Unlock()
+	var res retention.Offset
+	found := false
+	for o := range c.inflight {
+		if !found || o < res {
+			res = o
+			found = true
+		}
+	}
+	return res, found
+}
+
+func (c *ackConsumer[Msg]) ack(d *delivery[Msg]) {
+	c.Lock()
+	defer c.Unlock()
+	i := d.inflight
+	i.acked = true
+	if c.inflight[i.offset] == i {
+		delete(c.inflight, i.offset)
+	}
+	c.wake.Notify()
+}
+
+func (c *ackConsumer[Msg]) nack(d *delivery[Msg], err error) {
+	c.Lock()
+	defer c.Unlock()
+	i := d.inflight
+	switch {
+	case i.acked || d.nacked:
+		return
+	case !d.sent:
+		d.nacked = true
+		d.nackErr = err
+	case i.attempts == d.attempt:
+		d.nacked = true
+		c.retry(i, err)
+	}
+}
+
+func (c *ackConsumer[Msg]) retry(i *inflight[Msg], err error) {
+	i.nacked = true
+	i.lastErr = err
+	i.due = c.topic.Clock.Now().Add(i.retry())
+	c.wake.Notify()
+}
+
+func (d *delivery[Msg]) Message() Msg {
+	return d.inflight.msg
+}
+
+func (d *delivery[_]) Offset() retention.Offset {
+	return d.inflight.offset
+}
+
+func (d *delivery[_]) Attempt() int {
+	return d.attempt
+}
+
+func (d *delivery[_]) ConsumerID() id.ID {
+	return d.receiver
+}
+
+func (d *delivery[_]) Ack() {
+	d.consumer.ack(d)
+}
+
+func (d *delivery[_]) Nack(err error) {
+	d.consumer.nack(d, err)
+}
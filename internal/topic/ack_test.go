@@ -0,0 +1,376 @@
+package topic_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/clock/clocktest"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+func TestAckConsumer(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string](config.Permanent)
+	p := top.NewProducer()
+	p.Send() <- "first value"
+	p.Send() <- "second value"
+
+	c := top.NewAckConsumer()
+	d := message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("first value", d.Message())
+	as.Equal(topic.Offset(0), d.Offset())
+	as.Equal(1, d.Attempt())
+	as.Equal(c.ID(), d.ConsumerID())
+	d.Ack()
+
+	d = message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("second value", d.Message())
+	d.Ack()
+
+	_, ok := message.Poll[topic.Delivery[string]](c, 20*time.Millisecond)
+	as.False(ok)
+
+	c.Close()
+	as.True(closer.IsClosed(c))
+	p.Close()
+}
+
+func TestAckVisibilityTimeout(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string](config.Permanent)
+	p := top.NewProducer()
+	p.Send() <- "first value"
+	p.Send() <- "second value"
+
+	c := top.NewAckConsumer(topic.VisibilityTimeout(20 * time.Millisecond))
+	d := message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("first value", d.Message())
+
+	d = message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("second value", d.Message())
+	d.Ack()
+
+	// the first message was never acknowledged
+	d = message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("first value", d.Message())
+	as.Equal(2, d.Attempt())
+	d.Ack()
+
+	_, ok := message.Poll[topic.Delivery[string]](c, 50*time.Millisecond)
+	as.False(ok)
+	c.Close()
+	p.Close()
+}
+
+func TestNackRedelivery(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string](config.Permanent)
+	p := top.NewProducer()
+	p.Send() <- "first value"
+
+	c := top.NewAckConsumer(
+		topic.Redelivery(backoff.MakeFixedGenerator(time.Millisecond)),
+	)
+	d := message.MustReceive[topic.Delivery[string]](c)
+	as.Equal(1, d.Attempt())
+	d.Nack(errors.New("failed"))
+	d.Nack(errors.New("ignored"))
+
+	d = message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("first value", d.Message())
+	as.Equal(2, d.Attempt())
+	d.Ack()
+	d.Nack(errors.New("too late"))
+
+	_, ok := message.Poll[topic.Delivery[string]](c, 20*time.Millisecond)
+	as.False(ok)
+	c.Close()
+	p.Close()
+}
+
+func TestAckRetention(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+	top := internal.Make[int](config.Consumed)
+	l := top.(*internal.Topic[int])
+	c := top.NewAckConsumer()
+	for i := 0; i < segmentSize*2; i++ {
+		as.Nil(l.Put(i))
+	}
+
+	var first topic.Delivery[int]
+	for i := 0; i < segmentSize+1; i++ {
+		d := message.MustReceive[topic.Delivery[int]](c)
+		if i == 0 {
+			first = d
+			continue
+		}
+		d.Ack()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	as.Equal(topic.Offset(0), top.(topic.Locator).Start())
+
+	first.Ack()
+	time.Sleep(20 * time.Millisecond)
+	l.Get(0) // wakes the vacuum
+	time.Sleep(20 * time.Millisecond)
+	as.Equal(topic.Offset(segmentSize), top.(topic.Locator).Start())
+	c.Close()
+}
+
+func TestAckConsumerTopicClose(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](config.Permanent)
+	p := top.NewProducer()
+	p.Send() <- 1
+	c := top.NewAckConsumer()
+	top.Close()
+
+	d := message.MustReceive[topic.Delivery[int]](c)
+	as.Equal(1, d.Message())
+	d.Ack()
+	_, ok := message.Receive[topic.Delivery[int]](c)
+	as.False(ok)
+}
+
+func TestAckGroup(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](config.Permanent)
+	l := top.(*internal.Topic[int])
+	for i := 0; i < 3; i++ {
+		as.Nil(l.Put(i))
+	}
+
+	redelivery := topic.Redelivery(backoff.MakeFixedGenerator(time.Millisecond))
+	m1 := top.NewAckConsumer(topic.Group("workers"), redelivery)
+	m2 := top.NewAckConsumer(topic.Group("workers"))
+	as.NotEqual(m1.ID(), m2.ID())
+	as.Equal(2, l.Consumers())
+
+	// a message that one member fails to process is redelivered
+	d := message.MustReceive[topic.Delivery[int]](m1)
+	as.Equal(0, d.Message())
+	as.Equal(m1.ID(), d.ConsumerID())
+	d.Nack(errors.New("failed"))
+	m1.Close()
+	_, ok := <-m1.Receive()
+	as.False(ok)
+
+	var res []int
+	for i := 0; i < 3; i++ {
+		d = message.MustReceive[topic.Delivery[int]](m2)
+		as.Equal(m2.ID(), d.ConsumerID())
+		if d.Message() == 0 {
+			as.Equal(2, d.Attempt())
+		}
+		res = append(res, d.Message())
+		if d.Message() != 2 {
+			d.Ack()
+		}
+	}
+	as.ElementsMatch([]int{0, 1, 2}, res)
+	m2.Close()
+	as.Equal(0, l.Consumers())
+
+	// a retired group resumes from its lowest unacknowledged message
+	m3 := top.NewAckConsumer(topic.Group("workers"))
+	m4 := top.NewAckConsumer(topic.Group("workers"))
+	d = message.MustReceive[topic.Delivery[int]](m3)
+	as.Equal(2, d.Message())
+	as.Equal(1, d.Attempt())
+	d.Ack()
+
+	// a member that leaves an idle group is closed
+	m4.Close()
+	_, ok = <-m4.Receive()
+	as.False(ok)
+	m3.Close()
+	top.Close()
+}
+
+func TestAckGroupTopicClose(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](config.Permanent)
+	as.Nil(top.(*internal.Topic[int]).Put(1))
+
+	m1 := top.NewAckConsumer(topic.Group("workers"))
+	m2 := top.NewAckConsumer(topic.Group("workers"))
+	top.Close()
+
+	var d topic.Delivery[int]
+	select {
+	case d = <-m1.Receive():
+	case d = <-m2.Receive():
+	}
+	as.Equal(1, d.Message())
+	d.Ack()
+
+	_, ok := message.Receive[topic.Delivery[int]](m1)
+	as.False(ok)
+	_, ok = message.Receive[topic.Delivery[int]](m2)
+	as.False(ok)
+	as.True(closer.IsClosed(m1))
+	as.True(closer.IsClosed(m2))
+}
+
+func TestDeadLetter(t *testing.T) {
+	as := assert.New(t)
+	dlq := internal.Make[topic.DeadLetter[string]](config.Permanent)
+	top := internal.Make[string](
+		config.Permanent, config.DeadLetter[string](dlq, 2),
+	)
+	p := top.NewProducer()
+	p.Send() <- "poison"
+
+	c := top.NewAckConsumer(
+		topic.Redelivery(backoff.MakeFixedGenerator(time.Millisecond)),
+	)
+	d := message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("poison", d.Message())
+	d.Nack(errors.New("first failure"))
+
+	d = message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("poison", d.Message())
+	as.Equal(2, d.Attempt())
+	d.Nack(errors.New("second failure"))
+
+	p.Send() <- "healthy"
+	d = message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("healthy", d.Message())
+	d.Ack()
+
+	dc := dlq.NewConsumer()
+	dl := message.MustReceive[topic.DeadLetter[string]](dc)
+	as.Equal("poison", dl.Message)
+	as.Equal(topic.Offset(0), dl.Offset)
+	as.Equal(2, dl.Attempts)
+	as.Equal("second failure", dl.LastError)
+	as.Equal(c.ID(), dl.ConsumerID)
+
+	_, ok := message.Poll[topic.Delivery[string]](c, 20*time.Millisecond)
+	as.False(ok)
+	c.Close()
+	dc.Close()
+	p.Close()
+}
+
+func TestDeadLetterVisibilityTimeout(t *testing.T) {
+	as := assert.New(t)
+	dlq := internal.Make[topic.DeadLetter[int]](config.Permanent)
+	top := internal.Make[int](config.DeadLetter[int](dlq, 1))
+	as.Nil(top.(*internal.Topic[int]).Put(42))
+
+	c := top.NewAckConsumer(topic.VisibilityTimeout(10 * time.Millisecond))
+	d := message.MustReceive[topic.Delivery[int]](c)
+	as.Equal(42, d.Message())
+
+	dc := dlq.NewConsumer()
+	dl := message.MustReceive[topic.DeadLetter[int]](dc)
+	as.Equal(42, dl.Message)
+	as.Equal(1, dl.Attempts)
+	as.Equal(topic.ErrVisibilityTimeout, dl.LastError)
+	c.Close()
+	dc.Close()
+}
+
+func TestPersistentDeadLetter(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	dlq := internal.Make[topic.DeadLetter[string]](config.Persistent(dir))
+	top := internal.Make[string](config.DeadLetter[string](dlq, 1))
+	as.Nil(top.(*internal.Topic[string]).Put("poison"))
+
+	c := top.NewAckConsumer()
+	d := message.MustReceive[topic.Delivery[string]](c)
+	d.Nack(errors.New("failed"))
+	as.Eventually(func() bool {
+		return dlq.Length() == 1
+	}, time.Second, time.Millisecond)
+	c.Close()
+	top.Close()
+	dlq.Close()
+
+	// the dead letters survive being stored and reopened
+	dlq, err := internal.Open[topic.DeadLetter[string]](config.Persistent(dir))
+	as.Nil(err)
+	dc := dlq.NewConsumer()
+	dl := message.MustReceive[topic.DeadLetter[string]](dc)
+	as.Equal("poison", dl.Message)
+	as.Equal(1, dl.Attempts)
+	as.Equal("failed", dl.LastError)
+	dc.Close()
+	dlq.Close()
+}
+
+func TestAckVisibilityTimeoutClock(t *testing.T) {
+	as := assert.New(t)
+	clk := clocktest.Make(time.Unix(0, 0))
+	top := internal.Make[string](config.Permanent, config.Clock(clk))
+	as.Nil(top.(*internal.Topic[string]).Put("value"))
+
+	c := top.NewAckConsumer(topic.VisibilityTimeout(time.Minute))
+	d := message.MustReceive[topic.Delivery[string]](c)
+	as.Equal(1, d.Attempt())
+
+	// no real time passes on the Clock, so nothing is redelivered
+	_, ok := message.Poll[topic.Delivery[string]](c, 20*time.Millisecond)
+	as.False(ok)
+
+	clk.Advance(time.Minute)
+	d = message.MustReceive[topic.Delivery[string]](c)
+	as.Equal("value", d.Message())
+	as.Equal(2, d.Attempt())
+	d.Ack()
+
+	c.Close()
+	top.Close()
+}
+
+func TestAckExpiry(t *testing.T) {
+	as := assert.New(t)
+	clk := clocktest.Make(time.Unix(0, 0))
+	policy := &expiredPolicy{}
+	top := internal.Make[int](
+		config.Clock(clk),
+		config.TTL(time.Minute),
+		config.RetentionPolicy(policy),
+	)
+	l := top.(*internal.Topic[int])
+	as.Nil(l.Put(0))
+
+	c := top.NewAckConsumer()
+	d := message.MustReceive[topic.Delivery[int]](c)
+	as.Equal(0, d.Message())
+	d.Nack(errors.New("failed"))
+
+	// the message expires before it's due again, so it's dropped
+	clk.Advance(time.Hour)
+	_, ok := message.Poll[topic.Delivery[int]](c, 20*time.Millisecond)
+	as.False(ok)
+
+	// fill the first segment so that it's vacuumed, which also removes the
+	// expired entry from the Log
+	segmentSize := config.DefaultSegmentIncrement
+	for i := 1; i <= segmentSize; i++ {
+		as.Nil(l.Put(i))
+	}
+	d = message.MustReceive[topic.Delivery[int]](c)
+	as.Equal(1, d.Message())
+	as.Equal(1, d.Attempt())
+	d.Ack()
+	as.Eventually(func() bool {
+		return policy.expired.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	c.Close()
+	top.Close()
+}
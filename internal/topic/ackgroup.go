@@ -0,0 +1,92 @@
+package topic
+
+import (
+	"sync"
+
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/topic"
+)
+
+// ackGroups manages the named consumer groups of a Topic whose members
+// acknowledge their messages. Each group shares a single ackConsumer among
+// its members, so that a message that one member fails to acknowledge may be
+// redelivered to any of them
+type ackGroups[Msg any] struct {
+	sync.Mutex
+	groups  map[string]*ackConsumer[Msg]
+	retired map[string]*ackConsumer[Msg]
+}
+
+func makeAckGroups[Msg any]() *ackGroups[Msg] {
+	return &ackGroups[Msg]{
+		groups:  map[string]*ackConsumer[Msg]{},
+		retired: map[string]*ackConsumer[Msg]{},
+	}
+}
+
+// join adds a new member to the group named by the ConsumerConfig, creating
+// the group if necessary using the rest of that configuration. A new group
+// resumes from the lowest Offset that it hadn't yet had acknowledged when it
+// was retired, if it has been, otherwise it starts at the configured Position
+func (g *ackGroups[Msg]) join(
+	t *Topic[Msg], cfg *topic.ConsumerConfig,
+) *member[topic.Delivery[Msg]] {
+	g.Lock()
+	defer g.Unlock()
+
+	name := cfg.Group
+	c, ok := g.groups[name]
+	if ok && c.roster.isStopped() {
+		g.retire(name, c)
+		ok = false
+	}
+	if !ok {
+		p := cfg.Position
+		if prev, ok := g.retired[name]; ok {
+			<-prev.roster.done
+			p = topic.AtOffset(prev.retained())
+			delete(g.retired, name)
+		}
+		cur := makeCursor(t, p)
+		c = makeAckConsumer(
+			cur, t.BackoffGenerator, cfg,
+			makeRoster[topic.Delivery[Msg]](),
+		)
+		t.trackCursor(cur)
+		g.groups[name] = c
+	}
+
+	return c.roster.join(t.Clock, c.reposition, func(i id.ID) {
+		g.leave(name, c, i)
+	})
+}
+
+// leave removes a member from its group. When the last member leaves, the
+// group is retired
+func (g *ackGroups[Msg]) leave(name string, c *ackConsumer[Msg], i id.ID) {
+	g.Lock()
+	defer g.Unlock()
+	if c.roster.remove(i) && g.groups[name] == c {
+		g.retire(name, c)
+	}
+}
+
+// counts returns the number of active groups, and the number of members that
+// have joined them
+func (g *ackGroups[_]) counts() (int, int) {
+	g.Lock()
+	defer g.Unlock()
+	members := 0
+	for _, c := range g.groups {
+		members += c.roster.count()
+	}
+	return len(g.groups), members
+}
+
+// retire closes a group's ackConsumer. Messages that it had delivered but
+// that were never acknowledged are redelivered if the group is rejoined
+func (g *ackGroups[Msg]) retire(name string, c *ackConsumer[Msg]) {
+	delete(g.groups, name)
+	g.retired[name] = c
+	c.Close()
+}
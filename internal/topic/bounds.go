@@ -0,0 +1,173 @@
+package topic
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/caravan/essentials/internal/sync/channel"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/overflow"
+	"github.com/caravan/essentials/topic/retention"
+)
+
+type (
+	// bounds tracks the number of messages and bytes retained by a bounded
+	// Log, and applies its overflow Strategy when there's no room left
+	bounds struct {
+		maxMessages uint64
+		maxBytes    uint64
+		overflow    overflow.Strategy
+		count       uint64
+		bytes       uint64
+		space       *channel.ReadyWait
+		closing     chan struct{}
+		closeOnce   sync.Once
+	}
+
+	// admission is the outcome of applying a Log's bounds to a new entry
+	admission uint8
+)
+
+const (
+	admitted admission = iota
+	dropped
+	rejected
+	blocked
+	evicting
+)
+
+func makeBounds(cfg *config.Config) (*bounds, error) {
+	if cfg.MaxMessages == 0 && cfg.MaxBytes == 0 {
+		return nil, nil
+	}
+	if cfg.Overflow == overflow.Block && cfg.MaxMessages != 0 &&
+		cfg.MaxMessages < uint64(cfg.SegmentIncrement) {
+		// segments are only discarded once they're full
+		return nil, errors.New(config.ErrMaxMessagesTooSmall)
+	}
+	if cfg.Overflow == overflow.Block && cfg.MaxBytes != 0 {
+		// a segment's bytes may run out before it fills and can be discarded
+		return nil, errors.New(config.ErrMaxBytesBlocking)
+	}
+	return &bounds{
+		maxMessages: cfg.MaxMessages,
+		maxBytes:    cfg.MaxBytes,
+		overflow:    cfg.Overflow,
+		space:       channel.MakeReadyWait(),
+		closing:     make(chan struct{}),
+	}, nil
+}
+
+// admit determines whether an entry of the specified size can be added. An
+// entry is always admitted to an empty Log, even if it exceeds the bounds
+func (b *bounds) admit(size uint64) admission {
+	count := atomic.LoadUint64(&b.count)
+	bytes := atomic.LoadUint64(&b.bytes)
+	switch {
+	case count == 0,
+		(b.maxMessages == 0 || count < b.maxMessages) &&
+			(b.maxBytes == 0 || bytes+size <= b.maxBytes):
+		return admitted
+	case b.overflow == overflow.DropNewest:
+		return dropped
+	case b.overflow == overflow.DropOldest:
+		return evicting
+	case b.overflow == overflow.Fail:
+		return rejected
+	default:
+		return blocked
+	}
+}
+
+func (b *bounds) add(size uint64) {
+	atomic.AddUint64(&b.count, 1)
+	atomic.AddUint64(&b.bytes, size)
+}
+
+func (b *bounds) release(size uint64) {
+	atomic.AddUint64(&b.count, ^uint64(0))
+	atomic.AddUint64(&b.bytes, ^(size - 1))
+	b.space.Notify()
+}
+
+// wait blocks until room may have been made, returning an error if the Log
+// is being closed or the wait is canceled instead
+func (b *bounds) wait(cancel <-chan struct{}) error {
+	select {
+	case <-b.closing:
+		return errors.New(topic.ErrTopicClosed)
+	case <-cancel:
+		return errors.New(topic.ErrProducerClosed)
+	case <-b.space.Wait():
+		return nil
+	}
+}
+
+func (b *bounds) close() {
+	b.closeOnce.Do(func() {
+		close(b.closing)
+	})
+}
+
+// putBounded applies the Log's bounds before adding an entry, retrying until
+// the entry has been admitted, dropped, or rejected, or the put is canceled
+func (l *Log[Msg]) putBounded(
+	entry *logEntry[Msg], cancel <-chan struct{},
+) (retention.Offset, error) {
+	b := l.bounds
+	force := false
+	waited := false
+	for {
+		l.tail.Lock()
+		a := b.admit(entry.size)
+		if a == admitted || force {
+			o, err := l.append(entry)
+			l.tail.Unlock()
+			if waited {
+				// wake the next waiting routine, if there is one
+				b.space.Notify()
+			}
+			return o, err
+		}
+		l.tail.Unlock()
+
+		switch a {
+		case dropped:
+			return 0, topic.ErrDropped
+		case rejected:
+			return 0, errors.New(topic.ErrTopicFull)
+		case evicting:
+			force = !l.dropOldest()
+		default:
+			if err := b.wait(cancel); err != nil {
+				return 0, err
+			}
+			waited = true
+		}
+	}
+}
+
+// dropOldest removes the oldest retained entry, regardless of the retention
+// policy. It returns false if there was nothing left to remove
+func (l *Log[Msg]) dropOldest() bool {
+	l.head.Lock()
+	defer l.head.Unlock()
+	for curr := l.head.segment; curr != nil; curr = curr.getNext() {
+		for i, n := uint32(0), curr.length(); i < n; i++ {
+			if l.remove(curr, i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unblock wakes any routines waiting for room in a bounded Log, failing their
+// puts
+func (l *Log[_]) unblock() {
+	if l.bounds != nil {
+		l.bounds.close()
+	}
+}
@@ -0,0 +1,272 @@
+package topic_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/overflow"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+func TestBoundedFail(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](
+		config.MaxMessages(3), config.Overflow(overflow.Fail),
+	)
+	l := top.(*internal.Topic[int])
+	for i := 0; i < 3; i++ {
+		as.Nil(l.Put(i))
+	}
+	as.EqualError(l.Put(3), topic.ErrTopicFull)
+	as.Equal(topic.Length(3), top.Length())
+	top.Close()
+}
+
+func TestBoundedDropNewest(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](
+		config.MaxMessages(3), config.Overflow(overflow.DropNewest),
+	)
+	l := top.(*internal.Topic[int])
+	for i := 0; i < 5; i++ {
+		as.Nil(l.Put(i))
+	}
+	c := top.NewConsumer()
+	as.Equal([]int{0, 1, 2}, drain(c))
+	c.Close()
+	top.Close()
+}
+
+func TestBoundedDropOldest(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](
+		config.MaxMessages(3), config.Overflow(overflow.DropOldest),
+	)
+	l := top.(*internal.Topic[int])
+	for i := 0; i < 5; i++ {
+		as.Nil(l.Put(i))
+	}
+	as.Equal(topic.Length(5), top.Length())
+	c := top.NewConsumer()
+	as.Equal([]int{2, 3, 4}, drain(c))
+	c.Close()
+	top.Close()
+}
+
+func TestBoundedBytes(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string](
+		config.MaxBytes(10), config.Overflow(overflow.DropOldest),
+	)
+	l := top.(*internal.Topic[string])
+	as.Nil(l.Put("first"))
+	as.Nil(l.Put("second"))
+	as.Nil(l.Put("third"))
+	c := top.NewConsumer()
+	as.Equal([]string{"third"}, drain(c))
+	c.Close()
+
+	// a message larger than the budget is still admitted to an empty Topic
+	as.Nil(l.Put("much too large"))
+	c = top.NewConsumer()
+	as.Equal([]string{"much too large"}, drain(c))
+	c.Close()
+	top.Close()
+}
+
+func TestBoundedBlock(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+	top := internal.Make[int](
+		config.Consumed, config.MaxMessages(uint64(segmentSize)),
+	)
+	l := top.(*internal.Topic[int])
+	c := top.NewConsumer()
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(l.Put(i))
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- l.Put(segmentSize)
+	}()
+	select {
+	case <-done:
+		as.Fail("put should have blocked")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// consuming the full segment allows it to be discarded
+	for i := 0; i < segmentSize; i++ {
+		as.Equal(i, message.MustReceive[int](c))
+	}
+	as.Nil(<-done)
+	as.Equal(segmentSize, message.MustReceive[int](c))
+	c.Close()
+	top.Close()
+}
+
+func TestBoundedBlockClose(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+	top := internal.Make[int](config.MaxMessages(uint64(segmentSize)))
+	l := top.(*internal.Topic[int])
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(l.Put(i))
+	}
+
+	p := top.NewProducer()
+	p.Send() <- segmentSize
+	done := make(chan error)
+	go func() {
+		done <- l.Put(segmentSize)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	top.Close()
+	as.EqualError(<-done, topic.ErrTopicClosed)
+}
+
+func TestBoundedBlockProducerClose(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+	top := internal.Make[int](config.MaxMessages(uint64(segmentSize)))
+	l := top.(*internal.Topic[int])
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(l.Put(i))
+	}
+
+	// closing a Producer cancels the put it's blocked on
+	p := top.NewProducer()
+	p.Send() <- segmentSize
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		as.Fail("producer close should not have blocked")
+	}
+
+	p = top.NewProducer()
+	done := make(chan error)
+	go func() {
+		_, err := p.Produce(segmentSize)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	p.Close()
+	as.EqualError(<-done, topic.ErrProducerClosed)
+
+	ep := top.NewEnvelopeProducer()
+	ep.Send() <- topic.Envelope[int]{Message: segmentSize}
+	ep.Close()
+	as.Equal(topic.Length(segmentSize), top.Length())
+	top.Close()
+}
+
+func TestBoundedBlockKeyedClose(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+	opt := config.MaxMessages(uint64(segmentSize))
+
+	pt := internal.MakePartitioned[int](1, opt)
+	pr := internal.MakePriority[int](1, opt)
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(pt.(*internal.Partitioned[int]).Put(nil, i))
+		as.Nil(pr.(*internal.Prioritized[int]).Put(0, i))
+	}
+
+	kp := pt.NewProducer()
+	kp.Send() <- topic.Keyed[int]{Message: segmentSize}
+	kp.Close()
+	pp := pr.NewProducer()
+	pp.Send() <- topic.Prioritized[int]{Message: segmentSize}
+	pp.Close()
+
+	as.Equal(topic.Length(segmentSize), pt.Length())
+	as.Equal(topic.Length(segmentSize), pr.Length())
+	pt.Close()
+	pr.Close()
+}
+
+func TestBoundedPersistent(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	segmentSize := config.DefaultSegmentIncrement
+	opts := []config.Option{
+		config.Persistent(dir),
+		config.MaxMessages(10),
+		config.Overflow(overflow.DropOldest),
+	}
+
+	top := internal.Make[int](opts...)
+	l := top.(*internal.Topic[int])
+	for i := 0; i < segmentSize+8; i++ {
+		as.Nil(l.Put(i))
+	}
+
+	// the dropped entries of the full segment are rewritten as removed
+	// records, leaving only the two that are still retained
+	first := filepath.Join(dir, fmt.Sprintf("%020d.seg", 0))
+	size := int64(8 + segmentSize*16 + 2*len(fmt.Sprint(segmentSize-1)))
+	as.Eventually(func() bool {
+		info, err := os.Stat(first)
+		return err == nil && info.Size() == size
+	}, time.Second, 5*time.Millisecond)
+	top.Close()
+
+	top = internal.Make[int](opts...)
+	c := top.NewConsumer()
+	res := drain(c)
+	as.Equal(10, len(res))
+	as.Equal(segmentSize-2, res[0])
+	c.Close()
+	top.Close()
+}
+
+func TestBoundedTooSmall(t *testing.T) {
+	as := assert.New(t)
+	defer func() {
+		as.EqualError(recover().(error), config.ErrMaxMessagesTooSmall)
+	}()
+	internal.Make[int](config.MaxMessages(5))
+}
+
+func TestBoundedBytesBlocking(t *testing.T) {
+	as := assert.New(t)
+	_, err := internal.Open[string](config.Consumed, config.MaxBytes(10))
+	as.EqualError(err, config.ErrMaxBytesBlocking)
+
+	_, err = internal.Open[string](
+		config.MaxMessages(64), config.MaxBytes(10),
+		config.Overflow(overflow.Block),
+	)
+	as.EqualError(err, config.ErrMaxBytesBlocking)
+}
+
+func TestBoundedSizer(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string](
+		config.MaxBytes(2),
+		config.Overflow(overflow.DropOldest),
+		config.Sizer(func(any) int { return 1 }),
+	)
+	l := top.(*internal.Topic[string])
+	as.Nil(l.Put("first"))
+	as.Nil(l.Put("second"))
+	as.Nil(l.Put("third"))
+	c := top.NewConsumer()
+	as.Equal([]string{"second", "third"}, drain(c))
+	c.Close()
+	top.Close()
+}
@@ -0,0 +1,158 @@
+package topic
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/retention"
+)
+
+type (
+	// compaction indexes the Keys of a compacted Log's messages, tracking
+	// the Offsets of entries that can be removed once they're compacted
+	compaction[Msg any] struct {
+		sync.Mutex
+		latest     map[string]retention.Offset
+		superseded []retention.Offset
+		tombstones []tombstone
+	}
+
+	tombstone struct {
+		key    string
+		offset retention.Offset
+	}
+
+	// tombstoneQuery is called by Log in order to determine if a tombstone
+	// that is no longer superseded should be retained. Such a function is
+	// provided by a Topic in order to apply a retention.CompactedPolicy
+	tombstoneQuery[Msg any] func(retention.Offset, *logEntry[Msg]) bool
+)
+
+func makeCompaction[Msg any]() *compaction[Msg] {
+	return &compaction[Msg]{
+		latest: map[string]retention.Offset{},
+	}
+}
+
+// index records the Offset of a message as the latest for its Key, marking
+// the previous message with that Key as superseded
+func (c *compaction[Msg]) index(msg Msg, o retention.Offset) {
+	m, ok := any(msg).(topic.Compactable)
+	if !ok {
+		return
+	}
+	k := m.CompactionKey()
+	if k == nil {
+		return
+	}
+	key := string(k.Bytes())
+
+	c.Lock()
+	defer c.Unlock()
+	if prev, ok := c.latest[key]; ok {
+		c.superseded = append(c.superseded, prev)
+	}
+	c.latest[key] = o
+	if m.IsTombstone() {
+		c.tombstones = append(c.tombstones, tombstone{
+			key:    key,
+			offset: o,
+		})
+	}
+}
+
+// removals returns the Offsets below the specified bound that can be removed
+// from the Log. Those at or above it are left to a later compaction
+func (c *compaction[Msg]) removals(
+	l *Log[Msg], bound retention.Offset, retain tombstoneQuery[Msg],
+) []retention.Offset {
+	c.Lock()
+	defer c.Unlock()
+
+	start := retention.Offset(l.startOffset)
+	var res, keep []retention.Offset
+	for _, o := range c.superseded {
+		switch {
+		case o < start:
+		case o < bound:
+			res = append(res, o)
+		default:
+			keep = append(keep, o)
+		}
+	}
+	c.superseded = keep
+
+	var tombstones []tombstone
+	for _, t := range c.tombstones {
+		switch {
+		case c.latest[t.key] != t.offset:
+			// superseded, so it's already being removed
+		case t.offset < start:
+			delete(c.latest, t.key)
+		case t.offset >= bound:
+			tombstones = append(tombstones, t)
+		default:
+			if e := l.entryAt(t.offset); e != nil && retain(t.offset, e) {
+				tombstones = append(tombstones, t)
+				continue
+			}
+			delete(c.latest, t.key)
+			res = append(res, t.offset)
+		}
+	}
+	c.tombstones = tombstones
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+	return res
+}
+
+// entryAt returns the entry at the specified Offset, if it's still retained.
+// The caller must hold the Log's head lock
+func (l *Log[Msg]) entryAt(o retention.Offset) *logEntry[Msg] {
+	start := retention.Offset(l.startOffset)
+	if o < start {
+		return nil
+	}
+	pos := uint64(o - start)
+	curr := l.head.segment
+	for ; curr != nil && pos >= uint64(curr.cap); curr = curr.getNext() {
+		pos -= uint64(curr.cap)
+	}
+	if curr == nil || pos >= uint64(curr.length()) {
+		return nil
+	}
+	return curr.entry(uint32(pos))
+}
+
+// compact removes superseded entries and expired tombstones from the full
+// segments of the Log, rewriting the segment files of a persistent Log
+func (l *Log[Msg]) compact(retain tombstoneQuery[Msg]) {
+	if l.compaction == nil {
+		return
+	}
+
+	l.head.Lock()
+	defer l.head.Unlock()
+
+	base := retention.Offset(l.startOffset)
+	bound := base
+	for curr := l.head.segment; curr != nil && !curr.isActive(); {
+		bound += retention.Offset(curr.cap)
+		curr = curr.getNext()
+	}
+
+	removals := l.compaction.removals(l, bound, retain)
+	for curr := l.head.segment; curr != nil && len(removals) > 0; {
+		end := base + retention.Offset(curr.cap)
+		for len(removals) > 0 && removals[0] < end {
+			l.remove(curr, uint32(removals[0]-base))
+			removals = removals[1:]
+		}
+		base = end
+		curr = curr.getNext()
+	}
+	l.flush()
+}
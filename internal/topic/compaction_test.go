@@ -0,0 +1,193 @@
+package topic_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/retention"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+type setting struct {
+	Name    string
+	Value   int
+	Deleted bool
+}
+
+func (s setting) CompactionKey() topic.Key {
+	return topic.StringKey(s.Name)
+}
+
+func (s setting) IsTombstone() bool {
+	return s.Deleted
+}
+
+func drain[Msg any](c topic.Consumer[Msg]) []Msg {
+	var res []Msg
+	for {
+		e, ok := message.Poll[Msg](c, 20*time.Millisecond)
+		if !ok {
+			return res
+		}
+		res = append(res, e)
+	}
+}
+
+func offsetAfter[Msg any](l *internal.Topic[Msg], o retention.Offset) func() bool {
+	return func() bool {
+		_, res, _ := l.Get(o)
+		return res > o
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[setting](config.Compacted)
+	l := top.(*internal.Topic[setting])
+	as.Nil(l.Put(setting{Name: "single", Value: -1}))
+	for i := 0; i < segmentSize*2; i++ {
+		as.Nil(l.Put(setting{Name: fmt.Sprint(i % 4), Value: i}))
+	}
+	as.Eventually(offsetAfter(l, 1), time.Second, 5*time.Millisecond)
+
+	// superseded entries in full segments are removed, but Offsets and
+	// the active segment are left alone
+	as.Equal(topic.Length(segmentSize*2+1), top.Length())
+	as.Equal(retention.Offset(0), l.Start())
+	e, o, ok := l.Get(1)
+	as.True(ok)
+	as.Equal(retention.Offset(segmentSize*2-3), o)
+	as.Equal(segmentSize*2-4, e.Value)
+
+	c := top.NewConsumer()
+	res := drain(c)
+	as.Equal(setting{Name: "single", Value: -1}, res[0])
+	as.Equal(5, len(res))
+	c.Close()
+	top.Close()
+}
+
+func TestComposedCompaction(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[setting](config.RetentionPolicy(retention.And(
+		retention.MakeCompactedPolicy(), retention.MakeTimedPolicy(time.Hour),
+	)))
+	l := top.(*internal.Topic[setting])
+	for i := 0; i < segmentSize*2; i++ {
+		as.Nil(l.Put(setting{Name: fmt.Sprint(i % 4), Value: i}))
+	}
+	as.Eventually(offsetAfter(l, 0), time.Second, 5*time.Millisecond)
+
+	c := top.NewConsumer()
+	as.Equal(4, len(drain(c)))
+	c.Close()
+	top.Close()
+}
+
+func TestCompactionDiscardsSegments(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[setting](config.Compacted)
+	l := top.(*internal.Topic[setting])
+	for i := 0; i < segmentSize*2+1; i++ {
+		as.Nil(l.Put(setting{Name: "key", Value: i}))
+	}
+	as.Eventually(func() bool {
+		return l.Start() == retention.Offset(segmentSize*2)
+	}, time.Second, 5*time.Millisecond)
+
+	c := top.NewConsumer()
+	as.Equal(segmentSize*2, message.MustReceive[setting](c).Value)
+	c.Close()
+	top.Close()
+}
+
+func TestCompactionTombstones(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[setting](config.Compacted)
+	l := top.(*internal.Topic[setting])
+	c := top.NewConsumer()
+
+	as.Nil(l.Put(setting{Name: "deleted", Value: 1}))
+	as.Nil(l.Put(setting{Name: "deleted", Deleted: true}))
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(l.Put(setting{Name: fmt.Sprint(i), Value: i}))
+	}
+	as.Eventually(offsetAfter(l, 0), time.Second, 5*time.Millisecond)
+
+	// the tombstone is retained until the Consumer has moved past it
+	_, o, _ := l.Get(0)
+	as.Equal(retention.Offset(1), o)
+	as.True(message.MustReceive[setting](c).Deleted)
+	as.Equal(segmentSize, len(drain(c)))
+	as.Eventually(offsetAfter(l, 1), time.Second, 5*time.Millisecond)
+	c.Close()
+
+	// the Key has been deleted, so a new value is simply retained
+	as.Nil(l.Put(setting{Name: "deleted", Value: 2}))
+	c = top.NewConsumer(topic.StartAt(topic.AtOffset(topic.Offset(segmentSize))))
+	res := drain(c)
+	as.Equal(setting{Name: "deleted", Value: 2}, res[len(res)-1])
+	c.Close()
+	top.Close()
+}
+
+func TestCompactionUnkeyed(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[int](config.Compacted)
+	l := top.(*internal.Topic[int])
+	for i := 0; i < segmentSize*2; i++ {
+		as.Nil(l.Put(i))
+	}
+	time.Sleep(20 * time.Millisecond)
+	c := top.NewConsumer()
+	as.Equal(segmentSize*2, len(drain(c)))
+	c.Close()
+	top.Close()
+}
+
+func TestPersistentCompaction(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[setting](config.Compacted, config.Persistent(dir))
+	l := top.(*internal.Topic[setting])
+	as.Nil(l.Put(setting{Name: "single", Value: -1}))
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(l.Put(setting{Name: fmt.Sprint(i % 2), Value: i}))
+	}
+	as.Eventually(offsetAfter(l, 1), time.Second, 5*time.Millisecond)
+	top.Close()
+
+	top = internal.Make[setting](config.Compacted, config.Persistent(dir))
+	l = top.(*internal.Topic[setting])
+	as.Equal(topic.Length(segmentSize+1), top.Length())
+	e, o, ok := l.Get(1)
+	as.True(ok)
+	as.Equal(retention.Offset(segmentSize-1), o)
+	as.Equal(segmentSize-2, e.Value)
+
+	// the compaction index is rebuilt, so restored values are superseded
+	as.Nil(l.Put(setting{Name: "single", Value: -2}))
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(l.Put(setting{Name: "filler", Value: i}))
+	}
+	as.Eventually(offsetAfter(l, 0), time.Second, 5*time.Millisecond)
+	top.Close()
+}
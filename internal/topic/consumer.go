@@ -3,25 +3,44 @@ package topic
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/caravan/essentials/closer"
 	"github.com/caravan/essentials/id"
 	"github.com/caravan/essentials/internal/sync/channel"
 	"github.com/caravan/essentials/topic"
 	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/clock"
 )
 
-type consumer[Msg any] struct {
-	*cursor[Msg]
-	id      id.ID
-	channel chan Msg
-}
+type (
+	consumer[Msg any] struct {
+		*cursor[Msg]
+		id      id.ID
+		channel chan Msg
+		seeks   chan topic.Position
+		batches chan *batchRequest[Msg]
+	}
+
+	// batchRequest asks a consumer's routine to read up to max messages
+	// directly from its cursor, waiting until the deadline for at least one.
+	// The reply channel is closed if the consumer closes in the meantime
+	batchRequest[Out any] struct {
+		max      int
+		deadline time.Time
+		reply    chan []Out
+	}
+)
 
 func makeConsumer[Msg any](c *cursor[Msg], b backoff.Generator) *consumer[Msg] {
+	seeks := make(chan topic.Position)
+	batches := make(chan *batchRequest[Msg])
 	res := &consumer[Msg]{
 		cursor:  c,
 		id:      c.id,
-		channel: startConsumer(c, b),
+		channel: startConsumer(c, b, seeks, batches, c.head),
+		seeks:   seeks,
+		batches: batches,
 	}
 
 	if Debug.IsEnabled() {
@@ -39,24 +58,76 @@ func (c *consumer[Msg]) Receive() <-chan Msg {
 	return c.channel
 }
 
-func startConsumer[Msg any](c *cursor[Msg], b backoff.Generator) chan Msg {
-	ch := make(chan Msg)
+// ReceiveBatch returns up to max messages read directly from the cursor,
+// bypassing the Consumer's channel
+func (c *consumer[Msg]) ReceiveBatch(
+	max int, wait time.Duration,
+) ([]Msg, bool) {
+	if max <= 0 {
+		return nil, !closer.IsClosed(c)
+	}
+	r := &batchRequest[Msg]{
+		max:      max,
+		deadline: c.topic.Clock.Now().Add(wait),
+		reply:    make(chan []Msg, 1),
+	}
+	select {
+	case <-c.IsClosed():
+		return nil, false
+	case c.batches <- r:
+		res, ok := <-r.reply
+		return res, ok
+	}
+}
+
+func (c *consumer[_]) Seek(p topic.Position) {
+	p = resolve(c.topic, p)
+	select {
+	case <-c.IsClosed():
+	case c.seeks <- p:
+	}
+}
+
+// startConsumer starts a routine that sends whatever the read function returns
+// for the cursor's head to the returned channel, advancing the cursor with
+// each successful send. Batch requests are answered by the same routine, so
+// that they don't race with the channel. A nil batches channel is never read
+func startConsumer[Msg, Out any](
+	c *cursor[Msg], b backoff.Generator, seeks <-chan topic.Position,
+	batches <-chan *batchRequest[Out], read func() (Out, bool),
+) chan Out {
+	ch := make(chan Out)
 	next := b()
+	var pending *batchRequest[Out]
 	go func() {
+		timer := channel.MakeTimer(c.topic.Clock.NewTimer)
 		defer func() {
 			// probably because the channel was closed
 			recover()
+			timer.Stop()
 		}()
 		for {
 			select {
 			case <-c.IsClosed():
 				goto closed
 			default:
-				if e, ok := c.head(); ok {
+				if pending != nil {
+					if readBatch(c, pending, read) {
+						pending = nil
+						continue
+					}
+				}
+				if e, ok := read(); ok && pending == nil {
 					select {
 					case <-c.IsClosed():
 						goto closed
-					case <-channel.Timeout(next()):
+					case p := <-seeks:
+						// abandon the pending message
+						c.seek(p)
+						next = b()
+					case pending = <-batches:
+						// abandon the pending message in favor of the batch
+					case <-timer.Reset(next()):
 						// allow retention policies to kick in while waiting
 						// for a channel read to happen
 					case ch <- e:
@@ -64,23 +135,118 @@ func startConsumer[Msg any](c *cursor[Msg], b backoff.Generator) chan Msg {
 						c.advance()
 						next = b()
 					}
+				} else if !ok && c.drained() {
+					// the Topic is closed and has been drained
+					c.Close()
+					goto closed
 				} else {
 					// Wait for something to happen
 					select {
 					case <-c.IsClosed():
 						goto closed
-					case <-channel.Timeout(next()):
+					case p := <-seeks:
+						c.seek(p)
+						next = b()
+					case pending = <-idle(pending, batches):
+					case <-c.closing():
+					case <-timer.Reset(batchWait(c, pending, next())):
 					case <-c.ready.Wait():
 					}
 				}
 			}
 		}
 	closed:
+		if pending != nil {
+			close(pending.reply)
+		}
 		close(ch)
 	}()
 	return ch
 }
 
+// readBatch reads up to the requested number of messages from the cursor,
+// advancing past each of them. It answers the request and returns true if
+// any messages were read or if its deadline has passed
+func readBatch[Msg, Out any](
+	c *cursor[Msg], r *batchRequest[Out], read func() (Out, bool),
+) bool {
+	var res []Out
+	for len(res) < r.max {
+		e, ok := read()
+		if !ok {
+			break
+		}
+		res = append(res, e)
+		c.advance()
+	}
+	if len(res) == 0 && c.topic.Clock.Now().Before(r.deadline) {
+		return false
+	}
+	r.reply <- res
+	return true
+}
+
+// idle returns the batches channel, or nil if a batch request is already
+// pending, in which case no other request should be accepted yet
+func idle[Out any](
+	r *batchRequest[Out], batches <-chan *batchRequest[Out],
+) <-chan *batchRequest[Out] {
+	if r != nil {
+		return nil
+	}
+	return batches
+}
+
+// batchWait returns the specified Duration or the time remaining until a
+// pending batch request's deadline, whichever is shorter
+func batchWait[Msg, Out any](
+	c *cursor[Msg], r *batchRequest[Out], d time.Duration,
+) time.Duration {
+	if r == nil {
+		return d
+	}
+	if w := r.deadline.Sub(c.topic.Clock.Now()); w < d {
+		return max(w, 0)
+	}
+	return d
+}
+
+// receiveBatch waits up to the specified Duration for a message from the
+// channel, and then receives up to max messages in total, for as long as
+// more of them are immediately available. It's used by Consumers that don't
+// own a cursor of their own
+func receiveBatch[Msg any](
+	clk clock.Clock, ch <-chan Msg, max int, wait time.Duration,
+) ([]Msg, bool) {
+	if max <= 0 {
+		return nil, true
+	}
+	var res []Msg
+	timer := clk.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			return nil, false
+		}
+		res = append(res, msg)
+	case <-timer.C():
+		return nil, true
+	}
+	for len(res) < max {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return res, true
+			}
+			res = append(res, msg)
+		default:
+			return res, true
+		}
+	}
+	return res, true
+}
+
 func consumerDebugFinalizer[Msg any](
 	wrap ErrorWrapper,
 ) func(c *consumer[Msg]) {
@@ -9,7 +9,9 @@ import (
 	"github.com/caravan/essentials/id"
 	"github.com/caravan/essentials/message"
 	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/clock/clocktest"
 	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/retention"
 	"github.com/stretchr/testify/assert"
 
 	internal "github.com/caravan/essentials/internal/topic"
@@ -216,3 +218,209 @@ func TestConsumerChannelClosed(t *testing.T) {
 	_, ok := <-ch
 	as.False(ok)
 }
+
+func TestConsumerStartPositions(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[any](config.Permanent)
+	p := top.NewProducer()
+	for i := 0; i < 100; i++ {
+		p.Send() <- i
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	c1 := top.NewConsumer(topic.StartAt(topic.Earliest))
+	as.Equal(0, message.MustReceive[any](c1))
+
+	c2 := top.NewConsumer(topic.StartAt(topic.AtOffset(42)))
+	as.Equal(42, message.MustReceive[any](c2))
+
+	c3 := top.NewConsumer(topic.StartAt(topic.Latest))
+	e, ok := message.Poll[any](c3, 10*time.Millisecond)
+	as.Nil(e)
+	as.False(ok)
+	p.Send() <- 100
+	as.Equal(100, message.MustReceive[any](c3))
+
+	p.Close()
+	c1.Close()
+	c2.Close()
+	c3.Close()
+}
+
+func TestConsumerStartAtTime(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[any](config.Permanent)
+	p := top.NewProducer()
+	for i := 0; i < 50; i++ {
+		p.Send() <- i
+	}
+	time.Sleep(10 * time.Millisecond)
+	mark := time.Now()
+	for i := 50; i < 100; i++ {
+		p.Send() <- i
+	}
+
+	c := top.NewConsumer(topic.StartAt(topic.AtTime(mark)))
+	as.Equal(50, message.MustReceive[any](c))
+
+	l := top.(topic.Locator)
+	as.Equal(retention.Offset(50), l.Find(mark))
+	as.Equal(l.End(), l.Find(time.Now()))
+
+	p.Close()
+	c.Close()
+}
+
+func TestConsumerSeek(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[any](config.Permanent)
+	p := top.NewProducer()
+	for i := 0; i < 10; i++ {
+		p.Send() <- i
+	}
+
+	c := top.NewConsumer()
+	as.Equal(0, message.MustReceive[any](c))
+	as.Equal(1, message.MustReceive[any](c))
+
+	c.Seek(topic.AtOffset(7))
+	as.Equal(7, message.MustReceive[any](c))
+	as.Equal(8, message.MustReceive[any](c))
+
+	c.Seek(topic.Earliest)
+	as.Equal(0, message.MustReceive[any](c))
+
+	c.Seek(topic.Latest)
+	p.Send() <- 10
+	as.Equal(10, message.MustReceive[any](c))
+
+	p.Close()
+	c.Close()
+	c.Seek(topic.Earliest) // doesn't block once closed
+}
+
+func TestConsumerReceiveBatch(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[int]()
+	p := top.NewProducer()
+	c := top.NewConsumer()
+
+	offsets, err := p.ProduceBatch([]int{0, 1, 2, 3, 4})
+	as.Nil(err)
+	as.Equal([]topic.Offset{0, 1, 2, 3, 4}, offsets)
+
+	res, ok := c.ReceiveBatch(3, 0)
+	as.True(ok)
+	as.Equal([]int{0, 1, 2}, res)
+
+	// the channel picks up where the batch left off
+	as.Equal(3, message.MustReceive[int](c))
+
+	res, ok = c.ReceiveBatch(10, time.Second)
+	as.True(ok)
+	as.Equal([]int{4}, res)
+
+	res, ok = c.ReceiveBatch(10, 10*time.Millisecond)
+	as.True(ok)
+	as.Empty(res)
+
+	p.Close()
+	c.Close()
+	top.Close()
+}
+
+func TestConsumerReceiveBatchWait(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[int]()
+	p := top.NewProducer()
+	c := top.NewConsumer()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = p.ProduceBatch([]int{1, 2})
+	}()
+
+	res, ok := c.ReceiveBatch(10, time.Second)
+	as.True(ok)
+	as.Equal([]int{1, 2}, res)
+
+	top.Close()
+	res, ok = c.ReceiveBatch(10, time.Second)
+	as.False(ok)
+	as.Nil(res)
+	as.True(closer.IsClosed(c))
+}
+
+func TestGroupReceiveBatch(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[int]()
+	c := top.NewConsumer(topic.Group("workers"))
+	as.Nil(top.(*internal.Topic[int]).Put(42))
+
+	res, ok := c.ReceiveBatch(10, time.Second)
+	as.True(ok)
+	as.Equal([]int{42}, res)
+
+	c.Close()
+	res, ok = c.ReceiveBatch(10, 10*time.Millisecond)
+	as.False(ok)
+	as.Nil(res)
+	top.Close()
+}
+
+func BenchmarkIdleConsumers(b *testing.B) {
+	const idle = 1000
+	clk := clocktest.Make(time.Unix(0, 0))
+	top := internal.Make[int](config.Clock(clk))
+	consumers := make([]topic.Consumer[int], idle)
+	for i := range consumers {
+		consumers[i] = top.NewConsumer()
+	}
+
+	// every Consumer, plus the vacuum routine, waits on a Timer when idle
+	waitIdle := func() {
+		for clk.Timers() < idle+1 {
+			runtime.Gosched()
+		}
+	}
+	waitIdle()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		clk.Advance(time.Second)
+		waitIdle()
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(runtime.NumGoroutine()), "goroutines")
+
+	for _, c := range consumers {
+		c.Close()
+	}
+	top.Close()
+}
+
+func BenchmarkReceiveBatch(b *testing.B) {
+	top := internal.Make[int]()
+	p := top.NewProducer()
+	c := top.NewConsumer()
+	batch := make([]int, 100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = p.ProduceBatch(batch)
+		for n := 0; n < len(batch); {
+			res, _ := c.ReceiveBatch(len(batch)-n, time.Second)
+			n += len(res)
+		}
+	}
+	b.StopTimer()
+
+	p.Close()
+	c.Close()
+	top.Close()
+}
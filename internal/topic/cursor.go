@@ -2,10 +2,13 @@ package topic
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/caravan/essentials/closer"
 	"github.com/caravan/essentials/id"
 	"github.com/caravan/essentials/internal/sync/channel"
+	"github.com/caravan/essentials/topic"
 	"github.com/caravan/essentials/topic/retention"
 )
 
@@ -23,39 +26,158 @@ type (
 		topic  *Topic[Msg]
 		ready  *channel.ReadyWait
 		offset retention.Offset
+
+		// next is the Offset at which the cursor resumes reading the Log.
+		// It's only ahead of the cursor's position while a delayed entry
+		// that it had moved past is at its head
+		next retention.Offset
+
+		// waiting holds the delayed entries that the cursor has moved past
+		// because they weren't yet due
+		waiting waiting
+
+		// floor optionally reports an Offset below the cursor's position
+		// that must still be retained on its behalf
+		floor func() (retention.Offset, bool)
 	}
 )
 
-func makeCursor[Msg any](t *Topic[Msg]) *cursor[Msg] {
+func makeCursor[Msg any](t *Topic[Msg], p topic.Position) *cursor[Msg] {
 	cID := id.New()
 	ready := channel.MakeReadyWait()
-	if t.Length() != 0 {
+	offset := p(t)
+	if offset < t.End() {
 		ready.Notify()
 	}
 
 	return &cursor[Msg]{
-		id:    cID,
-		topic: t,
-		ready: ready,
+		id:     cID,
+		topic:  t,
+		ready:  ready,
+		offset: offset,
+		next:   offset,
 		Closer: makeCloser(func() {
-			ready.Close()
-			t.cursors.remove(cID)
 			t.observers.remove(cID)
+			t.cursors.remove(cID)
+			ready.Close()
 		}),
 	}
 }
 
 func (c *cursor[Msg]) head() (Msg, bool) {
-	if e, o, ok := c.topic.Get(c.offset); ok {
-		c.offset = o
-		return e, true
+	if e, ok := c.entry(); ok {
+		return e.msg, true
 	}
 	var zero Msg
 	return zero, false
 }
 
+// entry returns the log entry at the cursor's head. Delayed entries that
+// aren't yet due are moved past, so that they don't hold back the entries
+// that follow them, and are returned ahead of those entries once they're due
+func (c *cursor[Msg]) entry() (*logEntry[Msg], bool) {
+	now := c.topic.log.delayTime()
+	if e, o, ok := c.due(now); ok {
+		c.setPosition(o)
+		return e, true
+	}
+	for {
+		e, o, ok := c.topic.getEntry(c.next)
+		c.next = o
+		c.setPosition(o)
+		if !ok {
+			return nil, false
+		}
+		if !e.isPending(now) {
+			return e, true
+		}
+		c.waiting.add(o, e.notBefore())
+		c.next = o.Next()
+	}
+}
+
+// due returns the earliest of the delayed entries that the cursor moved past,
+// if it has since become due, forgetting any that are no longer retained
+func (c *cursor[Msg]) due(
+	now time.Time,
+) (*logEntry[Msg], retention.Offset, bool) {
+	for {
+		o, at, ok := c.waiting.peek()
+		if !ok || at.After(now) {
+			return nil, 0, false
+		}
+		if e, got, ok := c.topic.getEntry(o); ok && got == o {
+			return e, o, true
+		}
+		// the entry was discarded, or has expired
+		c.waiting.pop()
+	}
+}
+
 func (c *cursor[_]) advance() {
-	c.offset = c.offset.Next()
+	if o := c.position(); o < c.next {
+		// a delayed entry that had been moved past was at the head, and
+		// it's always the earliest of them that's due
+		c.waiting.pop()
+	} else {
+		c.next = o.Next()
+	}
+	c.setPosition(c.next)
+}
+
+func (c *cursor[_]) seek(p topic.Position) {
+	c.next = p(c.topic)
+	c.waiting.clear()
+	c.setPosition(c.next)
+}
+
+// resolve fixes a Position against the Topic when a Consumer's Seek is called,
+// rather than when its routine gets around to applying it, so that messages
+// put after Seek returns aren't skipped
+func resolve[Msg any](t *Topic[Msg], p topic.Position) topic.Position {
+	return topic.AtOffset(p(t))
+}
+
+// position returns the cursor's current Offset. The Offset is only ever
+// changed by the routine that owns the cursor, but may be read by others
+func (c *cursor[_]) position() retention.Offset {
+	return retention.Offset(atomic.LoadUint64((*uint64)(&c.offset)))
+}
+
+func (c *cursor[_]) setPosition(o retention.Offset) {
+	atomic.StoreUint64((*uint64)(&c.offset), uint64(o))
+}
+
+// retained returns the lowest Offset that must still be retained on the
+// cursor's behalf
+func (c *cursor[_]) retained() retention.Offset {
+	res := c.position()
+	if w, ok := c.waiting.first(); ok && w < res {
+		res = w
+	}
+	if c.floor != nil {
+		if f, ok := c.floor(); ok && f < res {
+			res = f
+		}
+	}
+	return res
+}
+
+// drained returns whether the Topic is closed and the cursor has moved past
+// every entry it will ever have. Delayed entries that aren't yet due don't
+// keep the cursor from being drained, and are never delivered
+func (c *cursor[_]) drained() bool {
+	return closer.IsClosed(c.topic) && c.next >= c.topic.End()
+}
+
+// closing returns a channel that's closed once the Topic is closed, or nil if
+// it already has been, so that a cursor waiting on entries that aren't yet
+// readable doesn't wake continuously
+func (c *cursor[_]) closing() <-chan struct{} {
+	if closer.IsClosed(c.topic) {
+		return nil
+	}
+	return c.topic.IsClosed()
 }
 
 func makeCursors[Msg any]() *cursors[Msg] {
@@ -79,12 +201,18 @@ func (c *cursors[_]) remove(i id.ID) {
 	delete(c.cursors, i)
 }
 
+func (c *cursors[_]) count() int {
+	c.RLock()
+	defer c.RUnlock()
+	return len(c.cursors)
+}
+
 func (c *cursors[_]) offsets() []retention.Offset {
 	c.RLock()
 	defer c.RUnlock()
 	res := make([]retention.Offset, 0, len(c.cursors))
 	for _, cursor := range c.cursors {
-		res = append(res, cursor.offset)
+		res = append(res, cursor.retained())
 	}
 	return res
 }
@@ -33,6 +33,7 @@ const (
 // Error messages
 const (
 	MsgInstantiationTrace = "stack at time of instantiation"
+	ErrDeadLetterNotSent  = "dead letter at offset %d from consumer %s could not be sent"
 )
 
 var (
@@ -93,6 +94,14 @@ func (d *debugger) TailLogTo(w io.Writer) {
 	}()
 }
 
+// reportError sends an error that can't be returned to a caller to the debug
+// Topic
+func reportError(err error) {
+	Debug.WithProducer(func(dp topic.Producer[error]) {
+		dp.Send() <- err
+	})
+}
+
 // WrapStackTrace returns an ErrorWrapper that attaches Stack information to an
 // error based on the call stack when this function is invoked
 func WrapStackTrace(msg string) ErrorWrapper {
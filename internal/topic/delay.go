@@ -0,0 +1,248 @@
+package topic
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/caravan/essentials/internal/sync/channel"
+	"github.com/caravan/essentials/topic/clock"
+	"github.com/caravan/essentials/topic/retention"
+)
+
+type (
+	// schedule wakes the observers of a Topic as its delayed entries become
+	// due, so that Consumers don't have to wait for their backoff to elapse.
+	// Its routine is only started once the first delayed entry is put, and
+	// stops when the Topic is closed
+	schedule struct {
+		sync.Mutex
+		clock   clock.Clock
+		due     dueTimes
+		changed *channel.ReadyWait
+		started bool
+	}
+
+	// dueTimes is a min-heap of the Times at which delayed entries are due
+	dueTimes []time.Time
+
+	// waiting holds the delayed entries that a cursor has moved past. They
+	// form a min-heap of the Times at which they're due, so that only the
+	// earliest must be checked, and are also kept in Offset order, so that
+	// the lowest can be retained. It's only changed by the routine that
+	// owns the cursor, but may be read by others
+	waiting struct {
+		sync.Mutex
+		due     waitingEntries
+		ordered []*waitingEntry
+	}
+
+	waitingEntries []*waitingEntry
+
+	waitingEntry struct {
+		offset  retention.Offset
+		at      time.Time
+		removed bool
+	}
+)
+
+func makeSchedule(c clock.Clock) *schedule {
+	return &schedule{
+		clock:   c,
+		changed: channel.MakeReadyWait(),
+	}
+}
+
+// add schedules a wake-up for the specified Time, starting the schedule's
+// routine if necessary. The notify function is called whenever entries
+// become due, until the closed channel is closed
+func (s *schedule) add(
+	at time.Time, notify func(), closed <-chan struct{},
+) {
+	s.Lock()
+	defer s.Unlock()
+	heap.Push(&s.due, at)
+	if !s.started {
+		s.started = true
+		go s.start(notify, closed)
+	}
+	s.changed.Notify()
+}
+
+func (s *schedule) start(notify func(), closed <-chan struct{}) {
+	timer := channel.MakeTimer(s.clock.NewTimer)
+	defer timer.Stop()
+	for {
+		var wait <-chan time.Time
+		if next, ok := s.next(); ok {
+			wait = timer.Reset(next.Sub(s.clock.Now()))
+		}
+		select {
+		case <-closed:
+			return
+		case <-s.changed.Wait():
+		case <-wait:
+			if s.popDue() {
+				notify()
+			}
+		}
+	}
+}
+
+func (s *schedule) next() (time.Time, bool) {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.due) == 0 {
+		return time.Time{}, false
+	}
+	return s.due[0], true
+}
+
+// popDue discards the Times that are now due, returning whether there were any
+func (s *schedule) popDue() bool {
+	s.Lock()
+	defer s.Unlock()
+	now := s.clock.Now()
+	res := false
+	for len(s.due) != 0 && !s.due[0].After(now) {
+		heap.Pop(&s.due)
+		res = true
+	}
+	return res
+}
+
+func (d dueTimes) Len() int {
+	return len(d)
+}
+
+func (d dueTimes) Less(i, j int) bool {
+	return d[i].Before(d[j])
+}
+
+func (d dueTimes) Swap(i, j int) {
+	d[i], d[j] = d[j], d[i]
+}
+
+func (d *dueTimes) Push(x any) {
+	*d = append(*d, x.(time.Time))
+}
+
+func (d *dueTimes) Pop() any {
+	old := *d
+	n := len(old)
+	res := old[n-1]
+	*d = old[:n-1]
+	return res
+}
+
+// notBefore returns the Time before which an entry must not be delivered,
+// which is zero if the entry wasn't delayed
+func (e *logEntry[_]) notBefore() time.Time {
+	if e.meta != nil {
+		return e.meta.notBefore
+	}
+	return time.Time{}
+}
+
+// delayTime returns the current Time if any of the Log's entries were
+// delayed, otherwise a zero Time, so that reads only consult the Clock when
+// they must
+func (l *Log[_]) delayTime() time.Time {
+	if l.delayed.Load() {
+		return l.clock.Now()
+	}
+	return time.Time{}
+}
+
+// isPending returns whether an entry has been delayed past the specified Time.
+// A zero Time is used when no entries of the Log have been delayed
+func (e *logEntry[_]) isPending(now time.Time) bool {
+	if now.IsZero() {
+		return false
+	}
+	return e.notBefore().After(now)
+}
+
+// add records a delayed entry that was moved past, along with the Time at
+// which it's due. Cursors read in Offset order, so its Offset is always
+// greater than those already recorded
+func (w *waiting) add(o retention.Offset, at time.Time) {
+	w.Lock()
+	defer w.Unlock()
+	e := &waitingEntry{offset: o, at: at}
+	heap.Push(&w.due, e)
+	w.ordered = append(w.ordered, e)
+}
+
+// peek returns the recorded entry that's due the earliest, if there is one
+func (w *waiting) peek() (retention.Offset, time.Time, bool) {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.due) == 0 {
+		return 0, time.Time{}, false
+	}
+	return w.due[0].offset, w.due[0].at, true
+}
+
+// pop forgets the recorded entry that's due the earliest
+func (w *waiting) pop() {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.due) != 0 {
+		heap.Pop(&w.due).(*waitingEntry).removed = true
+		w.trim()
+	}
+}
+
+// trim discards the forgotten entries from the front of the Offset order
+func (w *waiting) trim() {
+	for len(w.ordered) != 0 && w.ordered[0].removed {
+		w.ordered[0] = nil
+		w.ordered = w.ordered[1:]
+	}
+}
+
+func (w *waiting) clear() {
+	w.Lock()
+	defer w.Unlock()
+	w.due = nil
+	w.ordered = nil
+}
+
+// first returns the lowest recorded Offset, if there is one
+func (w *waiting) first() (retention.Offset, bool) {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.ordered) == 0 {
+		return 0, false
+	}
+	return w.ordered[0].offset, true
+}
+
+func (w waitingEntries) Len() int {
+	return len(w)
+}
+
+func (w waitingEntries) Less(i, j int) bool {
+	if w[i].at.Equal(w[j].at) {
+		return w[i].offset < w[j].offset
+	}
+	return w[i].at.Before(w[j].at)
+}
+
+func (w waitingEntries) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+}
+
+func (w *waitingEntries) Push(x any) {
+	*w = append(*w, x.(*waitingEntry))
+}
+
+func (w *waitingEntries) Pop() any {
+	old := *w
+	n := len(old)
+	res := old[n-1]
+	old[n-1] = nil
+	*w = old[:n-1]
+	return res
+}
@@ -0,0 +1,238 @@
+package topic_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/clock/clocktest"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+func TestDelayedDelivery(t *testing.T) {
+	as := assert.New(t)
+	start := time.Unix(1000, 0)
+	clk := clocktest.Make(start)
+	top := internal.Make[string](config.Clock(clk))
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[string]{Message: "later", Delay: time.Minute}
+	p.Send() <- topic.Envelope[string]{Message: "now"}
+	p.Close()
+
+	// the message that follows the delayed one isn't held back
+	c, err := top.NewEnvelopeConsumer()
+	as.Nil(err)
+	env := message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("now", env.Message)
+	as.Equal(topic.Offset(1), env.Offset)
+	as.True(env.NotBefore.IsZero())
+	_, ok := message.Poll[topic.Envelope[string]](c, 10*time.Millisecond)
+	as.False(ok)
+
+	clk.Advance(time.Minute)
+	env = message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("later", env.Message)
+	as.Equal(topic.Offset(0), env.Offset)
+	as.Equal(start.Add(time.Minute), env.NotBefore)
+	as.Zero(env.Delay)
+
+	c.Close()
+	top.Close()
+}
+
+func TestDelayedNotBefore(t *testing.T) {
+	as := assert.New(t)
+	start := time.Unix(1000, 0)
+	clk := clocktest.Make(start)
+	top := internal.Make[int](config.Clock(clk))
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[int]{
+		Message:   1,
+		NotBefore: start.Add(time.Hour),
+	}
+	p.Send() <- topic.Envelope[int]{
+		Message:   2,
+		NotBefore: start.Add(time.Minute),
+	}
+	p.Close()
+
+	c := top.NewConsumer()
+	_, ok := message.Poll[int](c, 10*time.Millisecond)
+	as.False(ok)
+
+	// a message due later doesn't hold back one that's due sooner
+	clk.Advance(time.Minute)
+	as.Equal(2, message.MustReceive[int](c))
+	_, ok = message.Poll[int](c, 10*time.Millisecond)
+	as.False(ok)
+
+	clk.Advance(time.Hour)
+	as.Equal(1, message.MustReceive[int](c))
+	c.Close()
+	top.Close()
+}
+
+func TestDelayedDueOrder(t *testing.T) {
+	as := assert.New(t)
+	start := time.Unix(1000, 0)
+	clk := clocktest.Make(start)
+	top := internal.Make[int](config.Clock(clk))
+	p := top.NewEnvelopeProducer()
+	for i, d := range []time.Duration{3, 1, 2} {
+		p.Send() <- topic.Envelope[int]{
+			Message:   i,
+			NotBefore: start.Add(d * time.Minute),
+		}
+	}
+	p.Close()
+
+	c := top.NewConsumer()
+	_, ok := message.Poll[int](c, 10*time.Millisecond)
+	as.False(ok)
+
+	// messages that become due together are received in due order
+	clk.Advance(time.Hour)
+	as.Equal(1, message.MustReceive[int](c))
+	as.Equal(2, message.MustReceive[int](c))
+	as.Equal(0, message.MustReceive[int](c))
+	c.Close()
+	top.Close()
+}
+
+func TestDelayedRetained(t *testing.T) {
+	as := assert.New(t)
+	start := time.Unix(1000, 0)
+	clk := clocktest.Make(start)
+	segmentSize := config.DefaultSegmentIncrement
+	top := internal.Make[int](config.Consumed, config.Clock(clk))
+	c := top.NewConsumer()
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[int]{Message: -1, Delay: time.Minute}
+	for i := 0; i < segmentSize*2; i++ {
+		p.Send() <- topic.Envelope[int]{Message: i}
+	}
+	p.Close()
+
+	// the consumed segments aren't discarded while the delayed message is
+	// still waiting to be received
+	for i := 0; i < segmentSize*2; i++ {
+		as.Equal(i, message.MustReceive[int](c))
+	}
+	_, ok := message.Poll[int](c, 10*time.Millisecond)
+	as.False(ok)
+
+	clk.Advance(time.Minute)
+	msg, ok := message.Poll[int](c, time.Second)
+	as.True(ok)
+	as.Equal(-1, msg)
+	c.Close()
+	top.Close()
+}
+
+func TestDelayedWakesConsumer(t *testing.T) {
+	as := assert.New(t)
+
+	// a Consumer with this backoff only rechecks the Topic when woken
+	top := internal.Make[string](config.FixedBackoffSequence(time.Hour))
+	c := top.NewConsumer()
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[string]{
+		Message: "delayed",
+		Delay:   20 * time.Millisecond,
+	}
+	p.Close()
+
+	msg, ok := message.Poll[string](c, time.Second)
+	as.True(ok)
+	as.Equal("delayed", msg)
+	c.Close()
+	top.Close()
+}
+
+func TestDelayedDrain(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string](config.Permanent)
+	c := top.NewConsumer()
+	g := top.NewConsumer(topic.Group("workers"))
+	ac := top.NewAckConsumer()
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[string]{Message: "delayed", Delay: time.Hour}
+	p.Send() <- topic.Envelope[string]{Message: "ready"}
+	p.Close()
+
+	// closing the Topic doesn't wait for its delayed messages to be due
+	top.Close()
+	msg, ok := message.Poll[string](c, time.Second)
+	as.True(ok)
+	as.Equal("ready", msg)
+	msg, ok = message.Poll[string](g, time.Second)
+	as.True(ok)
+	as.Equal("ready", msg)
+	d, ok := message.Poll[topic.Delivery[string]](ac, time.Second)
+	as.True(ok)
+	as.Equal("ready", d.Message())
+	d.Ack()
+
+	_, ok = message.Poll[string](c, time.Second)
+	as.False(ok)
+	_, ok = message.Poll[string](g, time.Second)
+	as.False(ok)
+	_, ok = message.Poll[topic.Delivery[string]](ac, time.Second)
+	as.False(ok)
+	as.True(closer.IsClosed(c))
+	as.True(closer.IsClosed(g))
+	as.True(closer.IsClosed(ac))
+}
+
+func TestPersistentDelay(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	due := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+
+	top := internal.Make[string](config.Persistent(dir))
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[string]{Message: "hello", NotBefore: due}
+	p.Close()
+	top.Close()
+
+	top = internal.Make[string](config.Persistent(dir))
+	c, err := top.NewEnvelopeConsumer()
+	as.Nil(err)
+	env := message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("hello", env.Message)
+	as.True(due.Equal(env.NotBefore))
+	as.True(env.ExpiresAt.IsZero())
+	c.Close()
+	top.Close()
+}
+
+func TestPersistentDelayedDrain(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	start := time.Unix(1000, 0)
+	clk := clocktest.Make(start)
+
+	top := internal.Make[string](config.Persistent(dir), config.Clock(clk))
+	c := top.NewConsumer()
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[string]{Message: "delayed", Delay: time.Hour}
+	p.Close()
+	top.Close()
+	_, ok := message.Poll[string](c, time.Second)
+	as.False(ok)
+
+	// the delayed message is still delivered once the Topic is reopened
+	top = internal.Make[string](config.Persistent(dir), config.Clock(clk))
+	c = top.NewConsumer()
+	clk.Advance(time.Hour)
+	msg, ok := message.Poll[string](c, time.Second)
+	as.True(ok)
+	as.Equal("delayed", msg)
+	c.Close()
+	top.Close()
+}
@@ -0,0 +1,152 @@
+package topic
+
+import (
+	"time"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/retention"
+)
+
+type (
+	// metadata is attached to a Log entry that was produced as an Envelope,
+	// or that was routed to a partition by its Key
+	metadata struct {
+		key       topic.Key
+		headers   topic.Headers
+		timestamp time.Time
+		expiresAt time.Time
+		notBefore time.Time
+	}
+
+	envelopeProducer[Msg any] struct {
+		closer.Closer
+		id      id.ID
+		channel chan topic.Envelope[Msg]
+	}
+
+	envelopeConsumer[Msg any] struct {
+		*cursor[Msg]
+		id      id.ID
+		channel chan topic.Envelope[Msg]
+		seeks   chan topic.Position
+	}
+)
+
+func makeEnvelopeProducer[Msg any](t *Topic[Msg]) *envelopeProducer[Msg] {
+	pID := id.New()
+	put := func(
+		env topic.Envelope[Msg], cancel <-chan struct{},
+	) (retention.Offset, error) {
+		if env.Delay > 0 && env.NotBefore.IsZero() {
+			env.NotBefore = t.Clock.Now().Add(env.Delay)
+		}
+		return t.put(&logEntry[Msg]{
+			msg:      env.Message,
+			producer: pID,
+			meta:     makeMetadata(env),
+		}, cancel)
+	}
+	var c closer.Closer
+	ch, stop := startProducer(put, nil, func(error) {
+		go c.Close()
+	})
+	c = makeCloser(func() {
+		stop()
+		t.producers.remove(pID)
+	})
+	if !t.producers.track(pID, c) {
+		c.Close()
+	}
+	return &envelopeProducer[Msg]{
+		Closer:  c,
+		id:      pID,
+		channel: ch,
+	}
+}
+
+func (p *envelopeProducer[_]) ID() id.ID {
+	return p.id
+}
+
+func (p *envelopeProducer[Msg]) Send() chan<- topic.Envelope[Msg] {
+	return p.channel
+}
+
+func makeEnvelopeConsumer[Msg any](
+	c *cursor[Msg], b backoff.Generator,
+) *envelopeConsumer[Msg] {
+	seeks := make(chan topic.Position)
+	return &envelopeConsumer[Msg]{
+		cursor:  c,
+		id:      c.id,
+		channel: startConsumer(c, b, seeks, nil, c.envelope),
+		seeks:   seeks,
+	}
+}
+
+func (c *envelopeConsumer[_]) ID() id.ID {
+	return c.id
+}
+
+func (c *envelopeConsumer[Msg]) Receive() <-chan topic.Envelope[Msg] {
+	return c.channel
+}
+
+func (c *envelopeConsumer[_]) Seek(p topic.Position) {
+	p = resolve(c.topic, p)
+	select {
+	case <-c.IsClosed():
+	case c.seeks <- p:
+	}
+}
+
+// envelope returns the entry at the cursor's head wrapped in an Envelope
+func (c *cursor[Msg]) envelope() (topic.Envelope[Msg], bool) {
+	if e, ok := c.entry(); ok {
+		res := e.envelope(c.position())
+		res.ExpiresAt = c.topic.log.expiresAt(e)
+		return res, true
+	}
+	return topic.Envelope[Msg]{}, false
+}
+
+func (e *logEntry[Msg]) envelope(o retention.Offset) topic.Envelope[Msg] {
+	res := topic.Envelope[Msg]{
+		Message:    e.msg,
+		Offset:     o,
+		ProducedAt: e.createdAt,
+		ProducerID: e.producer,
+	}
+	if m := e.meta; m != nil {
+		res.Key = m.key
+		res.Headers = m.headers
+		res.Timestamp = m.timestamp
+		res.NotBefore = m.notBefore
+	}
+	return res
+}
+
+// makeMetadata captures the metadata of an Envelope being produced, copying
+// its Headers so that they aren't shared with the Envelopes that are received
+func makeMetadata[Msg any](env topic.Envelope[Msg]) *metadata {
+	if env.Key == nil && len(env.Headers) == 0 && env.Timestamp.IsZero() &&
+		env.ExpiresAt.IsZero() && env.NotBefore.IsZero() {
+		return nil
+	}
+	res := &metadata{
+		key:       env.Key,
+		timestamp: env.Timestamp,
+		expiresAt: env.ExpiresAt,
+		notBefore: env.NotBefore,
+	}
+	if len(env.Headers) != 0 {
+		res.headers = make(topic.Headers, len(env.Headers))
+		for k, v := range env.Headers {
+			res.headers[k] = v
+		}
+	}
+	return res
+}
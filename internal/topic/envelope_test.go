@@ -0,0 +1,146 @@
+package topic_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+func TestEnvelope(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string]()
+	p := top.NewEnvelopeProducer()
+	c, err := top.NewEnvelopeConsumer()
+	as.Nil(err)
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	headers := topic.Headers{"trace-id": "abc"}
+	before := time.Now()
+	p.Send() <- topic.Envelope[string]{
+		Message:   "hello",
+		Key:       topic.StringKey("greeting"),
+		Headers:   headers,
+		Timestamp: ts,
+		Offset:    99, // ignored
+	}
+
+	env := message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("hello", env.Message)
+	as.Equal(topic.StringKey("greeting"), env.Key)
+	as.Equal(topic.Headers{"trace-id": "abc"}, env.Headers)
+	as.Equal(ts, env.Timestamp)
+	as.Equal(topic.Offset(0), env.Offset)
+	as.False(env.ProducedAt.Before(before))
+	as.Equal(p.ID(), env.ProducerID)
+
+	p.Close()
+	c.Close()
+	top.Close()
+}
+
+func TestEnvelopePlainMessages(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int]()
+	l := top.(*internal.Topic[int])
+	p := top.NewProducer()
+	c, err := top.NewEnvelopeConsumer()
+	as.Nil(err)
+
+	as.Nil(l.Put(1))
+	p.Send() <- 2
+
+	env := message.MustReceive[topic.Envelope[int]](c)
+	as.Equal(1, env.Message)
+	as.Equal(id.Nil, env.ProducerID)
+	as.Nil(env.Key)
+	as.Nil(env.Headers)
+	as.True(env.Timestamp.IsZero())
+
+	env = message.MustReceive[topic.Envelope[int]](c)
+	as.Equal(2, env.Message)
+	as.Equal(topic.Offset(1), env.Offset)
+	as.Equal(p.ID(), env.ProducerID)
+
+	c.Seek(topic.Earliest)
+	as.Equal(1, message.MustReceive[topic.Envelope[int]](c).Message)
+
+	p.Close()
+	c.Close()
+	top.Close()
+}
+
+func TestPersistentEnvelope(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	top := internal.Make[string](config.Persistent(dir))
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[string]{
+		Message:   "hello",
+		Key:       topic.StringKey("greeting"),
+		Headers:   topic.Headers{"a": "1", "b": ""},
+		Timestamp: ts,
+	}
+	p.Send() <- topic.Envelope[string]{Message: "bare"}
+	pID := p.ID()
+	p.Close()
+	as.Nil(top.(*internal.Topic[string]).Put("plain"))
+	top.Close()
+
+	top = internal.Make[string](config.Persistent(dir))
+	c, err := top.NewEnvelopeConsumer()
+	as.Nil(err)
+	env := message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("hello", env.Message)
+	as.Equal(topic.StringKey("greeting"), env.Key)
+	as.Equal(topic.Headers{"a": "1", "b": ""}, env.Headers)
+	as.True(ts.Equal(env.Timestamp))
+	as.Equal(pID, env.ProducerID)
+
+	env = message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("bare", env.Message)
+	as.Nil(env.Key)
+	as.Equal(pID, env.ProducerID)
+
+	env = message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("plain", env.Message)
+	as.Equal(id.Nil, env.ProducerID)
+	c.Close()
+	top.Close()
+}
+
+func TestPartitionedEnvelopeKey(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePartitioned[string](1)
+	p := top.NewProducer()
+	p.Send() <- topic.Keyed[string]{
+		Key:     topic.StringKey("key"),
+		Message: "routed",
+	}
+
+	c, err := top.Partitions()[0].NewEnvelopeConsumer()
+	as.Nil(err)
+	env := message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("routed", env.Message)
+	as.Equal(topic.StringKey("key"), env.Key)
+	as.Equal(p.ID(), env.ProducerID)
+	c.Close()
+	top.Close()
+}
+
+func TestEnvelopeGroup(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string]()
+	c, err := top.NewEnvelopeConsumer(topic.Group("group"))
+	as.Nil(c)
+	as.EqualError(err, topic.ErrEnvelopeGroupUnsupported)
+	top.Close()
+}
@@ -0,0 +1,82 @@
+package topic
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// expiresAt returns the Time at which an entry expires, which is zero if the
+// entry never expires
+func (l *Log[Msg]) expiresAt(e *logEntry[Msg]) time.Time {
+	if e.meta != nil && !e.meta.expiresAt.IsZero() {
+		return e.meta.expiresAt
+	}
+	if l.ttl != 0 {
+		return e.createdAt.Add(l.ttl)
+	}
+	return time.Time{}
+}
+
+// isExpired returns whether an entry has expired as of the specified Time. A
+// zero Time is used when no entries of the Log can expire
+func (l *Log[Msg]) isExpired(e *logEntry[Msg], now time.Time) bool {
+	if now.IsZero() {
+		return false
+	}
+	exp := l.expiresAt(e)
+	return !exp.IsZero() && !exp.After(now)
+}
+
+// canExpire returns whether any of the Log's entries can expire, either
+// because of a default time-to-live or an explicit expiration
+func (l *Log[_]) canExpire() bool {
+	return l.ttl != 0 || l.explicit.Load()
+}
+
+// expiredCount returns the number of entries that the Log has removed because
+// they expired
+func (l *Log[_]) expiredCount() uint64 {
+	return atomic.LoadUint64(&l.expired)
+}
+
+// countExpired records an expired message that an AckConsumer dropped rather
+// than delivering again
+func (l *Log[_]) countExpired() {
+	atomic.AddUint64(&l.expired, 1)
+}
+
+// expire removes the expired entries of full segments, so that they no longer
+// count toward the Log's bounds and retention, and so that segments whose
+// entries have all been removed can be discarded. Unless some entries have
+// explicit expirations, entries expire in order, so removal stops at the
+// first entry that hasn't expired
+func (l *Log[Msg]) expire() {
+	if !l.canExpire() {
+		return
+	}
+
+	l.head.Lock()
+	defer l.head.Unlock()
+	defer l.flush()
+
+	now := l.clock.Now()
+	ordered := !l.explicit.Load()
+	for curr := l.head.segment; curr != nil && !curr.isActive(); {
+		for i := uint32(0); i < curr.cap; i++ {
+			e := curr.entry(i)
+			if e == nil {
+				continue
+			}
+			if !l.isExpired(e, now) {
+				if ordered {
+					return
+				}
+				continue
+			}
+			if l.remove(curr, i) {
+				atomic.AddUint64(&l.expired, 1)
+			}
+		}
+		curr = curr.getNext()
+	}
+}
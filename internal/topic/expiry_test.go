@@ -0,0 +1,145 @@
+package topic_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/clock/clocktest"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/retention"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+// expiredPolicy retains everything, recording the expired entry count that
+// it was last given
+type expiredPolicy struct {
+	expired atomic.Uint64
+}
+
+func (*expiredPolicy) InitialState() retention.State {
+	return nil
+}
+
+func (p *expiredPolicy) Retain(
+	s retention.State, r *retention.Statistics,
+) (retention.State, bool) {
+	p.expired.Store(uint64(r.Log.Expired))
+	return s, true
+}
+
+func TestTTL(t *testing.T) {
+	as := assert.New(t)
+	clk := clocktest.Make(time.Unix(1000, 0))
+	top := internal.Make[string](config.Clock(clk), config.TTL(time.Minute))
+	l := top.(*internal.Topic[string])
+
+	as.Nil(l.Put("stale"))
+	clk.Advance(30 * time.Second)
+	as.Nil(l.Put("aging"))
+	clk.Advance(45 * time.Second)
+	as.Nil(l.Put("fresh"))
+
+	// the first message has expired, so it's skipped
+	msg, o, ok := l.Get(0)
+	as.True(ok)
+	as.Equal("aging", msg)
+	as.Equal(topic.Offset(1), o)
+
+	c := top.NewConsumer()
+	as.Equal("aging", message.MustReceive[string](c))
+
+	clk.Advance(time.Minute)
+	_, ok = message.Poll[string](c, 10*time.Millisecond)
+	as.False(ok)
+
+	c.Close()
+	top.Close()
+}
+
+func TestEnvelopeExpiry(t *testing.T) {
+	as := assert.New(t)
+	start := time.Unix(1000, 0)
+	clk := clocktest.Make(start)
+	top := internal.Make[string](config.Clock(clk), config.TTL(time.Hour))
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[string]{
+		Message:   "alert",
+		ExpiresAt: start.Add(time.Second),
+	}
+	p.Send() <- topic.Envelope[string]{Message: "default"}
+	p.Close()
+
+	c, err := top.NewEnvelopeConsumer()
+	as.Nil(err)
+	env := message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("alert", env.Message)
+	as.Equal(start.Add(time.Second), env.ExpiresAt)
+
+	env = message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("default", env.Message)
+	as.Equal(start.Add(time.Hour), env.ExpiresAt)
+
+	// the explicit expiration applies regardless of the Topic's default
+	clk.Advance(time.Minute)
+	c.Seek(topic.Earliest)
+	env = message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("default", env.Message)
+
+	c.Close()
+	top.Close()
+}
+
+func TestExpiryVacuum(t *testing.T) {
+	as := assert.New(t)
+	clk := clocktest.Make(time.Unix(1000, 0))
+	policy := &expiredPolicy{}
+	top := internal.Make[int](
+		config.Clock(clk),
+		config.TTL(time.Minute),
+		config.RetentionPolicy(policy),
+	)
+	l := top.(*internal.Topic[int])
+
+	segmentSize := config.DefaultSegmentIncrement
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(l.Put(i))
+	}
+	clk.Advance(time.Hour)
+	for i := 0; i < segmentSize; i++ {
+		as.Nil(l.Put(segmentSize + i))
+	}
+
+	// the retention Policy retains everything, but the first segment has
+	// entirely expired, so it's discarded anyway
+	as.Eventually(func() bool {
+		return l.Start() == topic.Offset(segmentSize) &&
+			policy.expired.Load() == uint64(segmentSize)
+	}, time.Second, time.Millisecond)
+	top.Close()
+}
+
+func TestPersistentExpiry(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	expires := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+
+	top := internal.Make[string](config.Persistent(dir))
+	p := top.NewEnvelopeProducer()
+	p.Send() <- topic.Envelope[string]{Message: "hello", ExpiresAt: expires}
+	p.Close()
+	top.Close()
+
+	top = internal.Make[string](config.Persistent(dir))
+	c, err := top.NewEnvelopeConsumer()
+	as.Nil(err)
+	env := message.MustReceive[topic.Envelope[string]](c)
+	as.Equal("hello", env.Message)
+	as.True(expires.Equal(env.ExpiresAt))
+	c.Close()
+	top.Close()
+}
@@ -0,0 +1,339 @@
+package topic
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/internal/sync/channel"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/clock"
+)
+
+type (
+	// groups manages the named consumer groups of a Topic, as well as the
+	// retired groups whose committed positions are resumed from when a new
+	// member joins
+	groups[Msg any] struct {
+		sync.Mutex
+		groups  map[string]*group[Msg]
+		retired map[string]*group[Msg]
+	}
+
+	// group shares a single cursor among its members. A dispatching routine
+	// delivers each message to exactly one of those members
+	group[Msg any] struct {
+		*roster[Msg]
+		name   string
+		cursor *cursor[Msg]
+		seeks  chan topic.Position
+	}
+
+	// roster tracks the members of a group on behalf of the routine that
+	// dispatches to them. Until that routine stops, it is the only one that
+	// sends to, or closes, a member's channel
+	roster[T any] struct {
+		sync.Mutex
+		members  map[id.ID]*member[T]
+		departed []chan T
+		stopped  bool
+		changed  *channel.ReadyWait
+		done     chan struct{}
+	}
+
+	// member is a Consumer that participates in a group
+	member[T any] struct {
+		closer.Closer
+		id      id.ID
+		channel chan T
+		clock   clock.Clock
+		seek    func(topic.Position, <-chan struct{})
+	}
+)
+
+func makeGroups[Msg any]() *groups[Msg] {
+	return &groups[Msg]{
+		groups:  map[string]*group[Msg]{},
+		retired: map[string]*group[Msg]{},
+	}
+}
+
+// join adds a new member to the named group, creating the group if necessary.
+// A new group resumes from its committed position, if it has one, otherwise
+// it starts at the specified Position
+func (g *groups[Msg]) join(
+	t *Topic[Msg], name string, p topic.Position,
+) *member[Msg] {
+	g.Lock()
+	defer g.Unlock()
+
+	grp, ok := g.groups[name]
+	if ok && grp.isStopped() {
+		g.retire(grp)
+		ok = false
+	}
+	if !ok {
+		if prev, ok := g.retired[name]; ok {
+			<-prev.done
+			p = topic.AtOffset(prev.cursor.position())
+			delete(g.retired, name)
+		}
+		grp = &group[Msg]{
+			roster: makeRoster[Msg](),
+			name:   name,
+			cursor: t.makeCursor(p),
+			seeks:  make(chan topic.Position),
+		}
+		g.groups[name] = grp
+		go grp.dispatch(t.BackoffGenerator)
+	}
+
+	return grp.join(t.Clock, grp.seek, func(i id.ID) {
+		g.leave(grp, i)
+	})
+}
+
+// leave removes a member from its group. When the last member leaves, the
+// group is retired
+func (g *groups[Msg]) leave(grp *group[Msg], i id.ID) {
+	g.Lock()
+	defer g.Unlock()
+	if grp.remove(i) && g.groups[grp.name] == grp {
+		g.retire(grp)
+	}
+}
+
+// counts returns the number of active groups, and the number of members that
+// have joined them
+func (g *groups[_]) counts() (int, int) {
+	g.Lock()
+	defer g.Unlock()
+	members := 0
+	for _, grp := range g.groups {
+		members += grp.count()
+	}
+	return len(g.groups), members
+}
+
+// retire closes a group's cursor, stopping its dispatching routine. The
+// position that routine stops at becomes the group's committed position
+func (g *groups[Msg]) retire(grp *group[Msg]) {
+	delete(g.groups, grp.name)
+	g.retired[grp.name] = grp
+	grp.cursor.Close()
+}
+
+// seek repositions the group's cursor on behalf of a member, unless that
+// member is closed first
+func (g *group[_]) seek(p topic.Position, closed <-chan struct{}) {
+	p = resolve(g.cursor.topic, p)
+	select {
+	case <-closed:
+	case <-g.cursor.IsClosed():
+	case g.seeks <- p:
+	}
+}
+
+// dispatch delivers messages from the group's cursor to whichever member is
+// first ready to receive them. Until it stops, it is the only routine that
+// sends to, or closes, a member's channel
+func (g *group[Msg]) dispatch(b backoff.Generator) {
+	c := g.cursor
+	timer := channel.MakeTimer(c.topic.Clock.NewTimer)
+	defer timer.Stop()
+	next := b()
+	drained := false
+	for !closer.IsClosed(c) {
+		members := g.channels()
+		e, ok := c.head()
+		if ok && len(members) > 0 {
+			cases := []reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.IsClosed())},
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(g.seeks)},
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(g.changed.Wait())},
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(
+					timer.Reset(next()),
+				)},
+			}
+			cases = appendSends(cases, members, func(*member[Msg]) Msg {
+				return e
+			})
+			switch chosen, recv, _ := reflect.Select(cases); chosen {
+			case 0, 2, 3:
+				// closed, membership changed, or allowing retention
+				// policies to kick in while waiting for a channel read
+			case 1:
+				c.seek(recv.Interface().(topic.Position))
+				next = b()
+			default:
+				c.advance()
+				next = b()
+			}
+			continue
+		}
+
+		if !ok && c.drained() {
+			// the Topic is closed and has been drained
+			drained = true
+			break
+		}
+
+		select {
+		case <-c.IsClosed():
+		case p := <-g.seeks:
+			c.seek(p)
+			next = b()
+		case <-g.changed.Wait():
+		case <-c.closing():
+		case <-timer.Reset(next()):
+		case <-c.ready.Wait():
+		}
+	}
+
+	g.stop()
+	if drained {
+		g.closeMembers()
+	}
+}
+
+func makeRoster[T any]() *roster[T] {
+	return &roster[T]{
+		members: map[id.ID]*member[T]{},
+		changed: channel.MakeReadyWait(),
+		done:    make(chan struct{}),
+	}
+}
+
+// join adds a new member to the roster. The leave function is called when the
+// member is closed
+func (r *roster[T]) join(
+	clk clock.Clock, seek func(topic.Position, <-chan struct{}),
+	leave func(id.ID),
+) *member[T] {
+	mID := id.New()
+	res := &member[T]{
+		id:      mID,
+		channel: make(chan T),
+		clock:   clk,
+		seek:    seek,
+	}
+	res.Closer = makeCloser(func() {
+		leave(mID)
+	})
+
+	r.Lock()
+	r.members[mID] = res
+	r.Unlock()
+	r.changed.Notify()
+	return res
+}
+
+// remove removes a member from the roster, returning whether it was the last
+func (r *roster[T]) remove(i id.ID) bool {
+	r.Lock()
+	defer r.Unlock()
+	if m, ok := r.members[i]; ok {
+		delete(r.members, i)
+		if r.stopped {
+			close(m.channel)
+		} else {
+			r.departed = append(r.departed, m.channel)
+			r.changed.Notify()
+		}
+	}
+	return len(r.members) == 0
+}
+
+func (r *roster[_]) count() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.members)
+}
+
+// channels returns the current members, closing the channels of those that
+// have departed
+func (r *roster[T]) channels() []*member[T] {
+	r.Lock()
+	defer r.Unlock()
+	r.closeDeparted()
+	res := make([]*member[T], 0, len(r.members))
+	for _, m := range r.members {
+		res = append(res, m)
+	}
+	return res
+}
+
+// release closes the channels of the members that have departed
+func (r *roster[_]) release() {
+	r.Lock()
+	defer r.Unlock()
+	r.closeDeparted()
+}
+
+func (r *roster[_]) closeDeparted() {
+	for _, ch := range r.departed {
+		close(ch)
+	}
+	r.departed = nil
+}
+
+// stop marks the roster's dispatching routine as having stopped, after which
+// members are responsible for closing their own channels
+func (r *roster[_]) stop() {
+	r.Lock()
+	defer r.Unlock()
+	r.closeDeparted()
+	r.stopped = true
+	close(r.done)
+}
+
+func (r *roster[_]) isStopped() bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.stopped
+}
+
+func (r *roster[T]) closeMembers() {
+	for _, m := range r.channels() {
+		m.Close()
+	}
+}
+
+// appendSends adds a case to the select cases for sending to each member,
+// with the value returned by the specified function
+func appendSends[T any](
+	cases []reflect.SelectCase, members []*member[T], value func(*member[T]) T,
+) []reflect.SelectCase {
+	for _, m := range members {
+		v := value(m)
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectSend,
+			Chan: reflect.ValueOf(m.channel),
+			Send: reflect.ValueOf(&v).Elem(),
+		})
+	}
+	return cases
+}
+
+func (m *member[_]) ID() id.ID {
+	return m.id
+}
+
+func (m *member[T]) Receive() <-chan T {
+	return m.channel
+}
+
+// ReceiveBatch returns up to max messages dispatched to the member
+func (m *member[T]) ReceiveBatch(
+	max int, wait time.Duration,
+) ([]T, bool) {
+	return receiveBatch(m.clock, m.channel, max, wait)
+}
+
+// Seek repositions the member's entire group
+func (m *member[_]) Seek(p topic.Position) {
+	m.seek(p, m.IsClosed())
+}
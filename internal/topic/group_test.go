@@ -0,0 +1,143 @@
+package topic_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+func TestGroupDeliversOnce(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](config.Consumed)
+	p := top.NewProducer()
+
+	const count = 1000
+	var mutex sync.Mutex
+	seen := map[int]int{}
+	var wg sync.WaitGroup
+	members := make([]topic.Consumer[int], 3)
+	for i := range members {
+		members[i] = top.NewConsumer(topic.Group("workers"))
+	}
+
+	received := make(chan int)
+	for _, m := range members {
+		wg.Add(1)
+		go func(c topic.Consumer[int]) {
+			defer wg.Done()
+			for e := range c.Receive() {
+				received <- e
+			}
+		}(m)
+	}
+
+	go func() {
+		for i := 0; i < count; i++ {
+			p.Send() <- i
+		}
+	}()
+
+	for i := 0; i < count; i++ {
+		e := <-received
+		mutex.Lock()
+		seen[e]++
+		mutex.Unlock()
+	}
+	as.Equal(count, len(seen))
+	for _, n := range seen {
+		as.Equal(1, n)
+	}
+
+	for _, m := range members {
+		m.Close()
+	}
+	wg.Wait()
+	p.Close()
+}
+
+func TestGroupMembership(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](config.Permanent)
+	p := top.NewProducer()
+	for i := 0; i < 10; i++ {
+		p.Send() <- i
+	}
+
+	m1 := top.NewConsumer(topic.Group("workers"))
+	as.Equal(0, message.MustReceive[int](m1))
+
+	m2 := top.NewConsumer(topic.Group("workers"))
+	m1.Close()
+	as.True(closer.IsClosed(m1))
+	_, ok := <-m1.Receive()
+	as.False(ok)
+	as.Equal(1, message.MustReceive[int](m2))
+
+	c := top.NewConsumer()
+	as.Equal(0, message.MustReceive[int](c))
+	c.Close()
+
+	m2.Seek(topic.AtOffset(8))
+	as.Equal(8, message.MustReceive[int](m2))
+	m2.Close()
+
+	// the group's committed position outlives its members
+	m3 := top.NewConsumer(topic.Group("workers"), topic.StartAt(topic.Earliest))
+	as.Equal(9, message.MustReceive[int](m3))
+	m3.Close()
+
+	other := top.NewConsumer(topic.Group("others"))
+	as.Equal(0, message.MustReceive[int](other))
+	other.Close()
+	p.Close()
+}
+
+func TestGroupTopicClose(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](config.Permanent)
+	p := top.NewProducer()
+	p.Send() <- 1
+	p.Send() <- 2
+
+	m1 := top.NewConsumer(topic.Group("workers"))
+	m2 := top.NewConsumer(topic.Group("workers"))
+	top.Close()
+
+	var res []int
+	for e := range m1.Receive() {
+		res = append(res, e)
+	}
+	for e := range m2.Receive() {
+		res = append(res, e)
+	}
+	as.ElementsMatch([]int{1, 2}, res)
+	as.True(closer.IsClosed(m1))
+	as.True(closer.IsClosed(m2))
+}
+
+func TestGroupRetention(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+	top := internal.Make[int](config.Consumed)
+	m := top.NewConsumer(topic.Group("workers"))
+	p := top.NewProducer()
+	for i := 0; i < segmentSize*2; i++ {
+		p.Send() <- i
+	}
+	for i := 0; i < segmentSize+1; i++ {
+		as.Equal(i, message.MustReceive[int](m))
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	as.Equal(topic.Offset(segmentSize), top.(topic.Locator).Start())
+	m.Close()
+	p.Close()
+}
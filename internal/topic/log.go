@@ -5,8 +5,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/caravan/essentials/id"
 	"github.com/caravan/essentials/internal/sync/mutex"
 	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/clock"
 	"github.com/caravan/essentials/topic/config"
 	"github.com/caravan/essentials/topic/retention"
 )
@@ -19,11 +21,25 @@ type (
 		capIncrement  uint32
 		head          headSegment[Msg]
 		tail          tailSegment[Msg]
+		store         *store[Msg]
+		compaction    *compaction[Msg]
+		bounds        *bounds
+		clock         clock.Clock
+		sizer         topic.Sizer
+		bytes         uint64
+		ttl           time.Duration
+		explicit      atomic.Bool
+		delayed       atomic.Bool
+		expired       uint64
+		dirty         int
 	}
 
 	logEntry[Msg any] struct {
 		msg       Msg
 		createdAt time.Time
+		size      uint64
+		producer  id.ID
+		meta      *metadata
 	}
 
 	headSegment[Msg any] struct {
@@ -44,7 +60,10 @@ type (
 		next    *segment[Msg]
 		len     uint32
 		cap     uint32
-		entries []*logEntry[Msg]
+		removed uint32
+		bytes   uint64
+		dirty   bool
+		entries []atomic.Pointer[logEntry[Msg]]
 	}
 
 	// retentionQuery is called by Log in order to determine if a segment
@@ -53,10 +72,35 @@ type (
 	retentionQuery[Msg any] func(*segment[Msg]) bool
 )
 
-func makeLog[Msg any](cfg *config.Config) *Log[Msg] {
-	return &Log[Msg]{
+func makeLog[Msg any](cfg *config.Config) (*Log[Msg], error) {
+	res := &Log[Msg]{
 		capIncrement: uint32(cfg.SegmentIncrement),
+		clock:        cfg.Clock,
+		sizer:        cfg.Sizer,
+		ttl:          cfg.TTL,
 	}
+	if _, ok := retention.Compaction(cfg.RetentionPolicy); ok {
+		res.compaction = makeCompaction[Msg]()
+	}
+	b, err := makeBounds(cfg)
+	if err != nil {
+		return nil, err
+	}
+	res.bounds = b
+	if cfg.StoragePath == "" {
+		return res, nil
+	}
+
+	s, err := openStore[Msg](cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.restore(s); err != nil {
+		_ = s.close()
+		return nil, err
+	}
+	res.store = s
+	return res, nil
 }
 
 func (l *Log[_]) start() retention.Offset {
@@ -69,18 +113,76 @@ func (l *Log[_]) length() topic.Length {
 	return topic.Length(res)
 }
 
+// byteSize returns the total size in bytes of the entries retained by the Log
+func (l *Log[_]) byteSize() uint64 {
+	return atomic.LoadUint64(&l.bytes)
+}
+
 func (l *Log[_]) nextCapacity() uint32 {
 	return l.capIncrement
 }
 
-func (l *Log[Msg]) put(msg Msg) {
-	entry := &logEntry[Msg]{
-		msg:       msg,
-		createdAt: time.Now(),
+// put adds an entry to the end of the Log, returning the Offset it was
+// assigned. A put that's blocked by the Log's bounds is abandoned if the
+// cancel channel is closed
+func (l *Log[Msg]) put(
+	entry *logEntry[Msg], cancel <-chan struct{},
+) (retention.Offset, error) {
+	entry.createdAt = l.clock.Now()
+	entry.size = l.measure(entry.msg)
+	if l.bounds != nil {
+		return l.putBounded(entry, cancel)
 	}
 
 	l.tail.Lock()
 	defer l.tail.Unlock()
+	return l.append(entry)
+}
+
+// putBatch adds entries to the end of the Log, returning the Offsets they were
+// assigned. Unless the Log is bounded, in which case each entry is admitted
+// separately, the entries are appended under a single acquisition of the tail
+// lock. If an error occurs, the Offsets appended before it are returned
+func (l *Log[Msg]) putBatch(
+	entries []*logEntry[Msg], cancel <-chan struct{},
+) ([]retention.Offset, error) {
+	now := l.clock.Now()
+	res := make([]retention.Offset, 0, len(entries))
+	for _, e := range entries {
+		e.createdAt = now
+		e.size = l.measure(e.msg)
+	}
+	if l.bounds != nil {
+		for _, e := range entries {
+			o, err := l.putBounded(e, cancel)
+			if err != nil {
+				return res, err
+			}
+			res = append(res, o)
+		}
+		return res, nil
+	}
+
+	l.tail.Lock()
+	defer l.tail.Unlock()
+	for _, e := range entries {
+		o, err := l.append(e)
+		if err != nil {
+			return res, err
+		}
+		res = append(res, o)
+	}
+	return res, nil
+}
+
+// append adds an entry to the end of the Log. The caller must hold the Log's
+// tail lock
+func (l *Log[Msg]) append(entry *logEntry[Msg]) (retention.Offset, error) {
+	if l.store != nil {
+		if err := l.store.write(entry); err != nil {
+			return 0, err
+		}
+	}
 	tail := l.tail.segment
 	if tail == nil {
 		l.head.Lock()
@@ -92,7 +194,60 @@ func (l *Log[Msg]) put(msg Msg) {
 	if s := tail.append(entry); s != tail {
 		l.tail.segment = s
 	}
-	atomic.AddUint64(&l.virtualLength, uint64(1))
+	o := atomic.AddUint64(&l.virtualLength, uint64(1)) - 1
+	atomic.AddUint64(&l.bytes, entry.size)
+	l.track(entry)
+	if l.compaction != nil {
+		l.compaction.index(entry.msg, retention.Offset(o))
+	}
+	if l.bounds != nil {
+		l.bounds.add(entry.size)
+	}
+	return retention.Offset(o), nil
+}
+
+// measure returns the size of a message, or zero if the Log has no Sizer
+// because nothing depends on the sizes of its messages
+func (l *Log[Msg]) measure(msg Msg) uint64 {
+	if l.sizer == nil {
+		return 0
+	}
+	return uint64(l.sizer(msg))
+}
+
+// restored applies the entries of a segment that was restored from storage to
+// the Log's compaction index, byte totals, and bounds
+func (l *Log[Msg]) restored(base retention.Offset, seg *segment[Msg]) {
+	for i := uint32(0); i < seg.len; i++ {
+		e := seg.entry(i)
+		if e == nil {
+			continue
+		}
+		if l.compaction != nil {
+			l.compaction.index(e.msg, base+retention.Offset(i))
+		}
+		e.size = l.measure(e.msg)
+		seg.bytes += e.size
+		l.bytes += e.size
+		l.track(e)
+		if l.bounds != nil {
+			l.bounds.add(e.size)
+		}
+	}
+}
+
+// track records whether an entry has an explicit expiration or was delayed,
+// so that reads only consult the Clock when they must
+func (l *Log[Msg]) track(e *logEntry[Msg]) {
+	if e.meta == nil {
+		return
+	}
+	if !e.meta.expiresAt.IsZero() {
+		l.explicit.Store(true)
+	}
+	if !e.meta.notBefore.IsZero() {
+		l.delayed.Store(true)
+	}
 }
 
 func (l *Log[Msg]) makeSegment() *segment[Msg] {
@@ -100,7 +255,7 @@ func (l *Log[Msg]) makeSegment() *segment[Msg] {
 	return &segment[Msg]{
 		log:     l,
 		cap:     c,
-		entries: make([]*logEntry[Msg], c),
+		entries: make([]atomic.Pointer[logEntry[Msg]], c),
 	}
 }
 
@@ -112,18 +267,51 @@ func (l *Log[Msg]) get(
 	curr := l.head.segment
 	l.head.RUnlock()
 
-	for ; curr != nil && pos >= uint64(curr.cap); curr = curr.getNext() {
-		pos -= uint64(curr.cap)
+	var now time.Time
+	if l.canExpire() {
+		now = l.clock.Now()
 	}
-	if curr != nil {
-		p := int(pos)
-		if p < int(curr.length()) {
-			return curr.entries[p], o, true
+	for curr != nil {
+		for ; curr != nil && pos >= uint64(curr.cap); curr = curr.getNext() {
+			pos -= uint64(curr.cap)
 		}
+		if curr == nil || pos >= uint64(curr.length()) {
+			break
+		}
+		if e := curr.entry(uint32(pos)); e != nil {
+			if !l.isExpired(e, now) {
+				return e, o, true
+			}
+		}
+		// the entry was removed or has expired, so move on to the next one
+		o++
+		pos++
 	}
 	return &logEntry[Msg]{}, o, false
 }
 
+func (l *Log[_]) find(t time.Time) retention.Offset {
+	l.head.RLock()
+	o := retention.Offset(l.startOffset)
+	curr := l.head.segment
+	l.head.RUnlock()
+
+	for ; curr != nil; curr = curr.getNext() {
+		n := curr.length()
+		if _, last, ok := curr.timeRange(); !ok || last.Before(t) {
+			o += retention.Offset(curr.cap)
+			continue
+		}
+		for i := uint32(0); i < n; i++ {
+			e := curr.entry(i)
+			if e != nil && !e.createdAt.Before(t) {
+				return o + retention.Offset(i)
+			}
+		}
+	}
+	return retention.Offset(l.length())
+}
+
 func (l *Log[_]) relativePos(o retention.Offset) (retention.Offset, uint64) {
 	eo := retention.Offset(l.startOffset)
 	if o < eo { // if requested is less than actual, we start at actual
@@ -144,12 +332,15 @@ func (l *Log[_]) canVacuum() bool {
 func (l *Log[Msg]) vacuum(retain retentionQuery[Msg]) {
 	l.head.Lock()
 	defer l.head.Unlock()
+	defer l.flush()
 
 	for curr := l.head.segment; curr != nil; {
-		if curr.isActive() || retain(curr) {
+		if curr.isActive() || !curr.isCompactedAway() && retain(curr) {
 			return // stop as soon as we see an active or retained segment
 		}
-		l.startOffset += uint64(curr.cap)
+		l.release(curr)
+		l.discard(l.startOffset)
+		atomic.AddUint64(&l.startOffset, uint64(curr.cap))
 		if curr = curr.getNext(); curr != nil {
 			l.head.segment = curr
 			continue
@@ -162,6 +353,78 @@ func (l *Log[Msg]) vacuum(retain retentionQuery[Msg]) {
 	}
 }
 
+// release accounts for the entries of a segment that is being discarded. The
+// caller must hold the Log's head lock
+func (l *Log[Msg]) release(s *segment[Msg]) {
+	if s.dirty {
+		s.dirty = false
+		l.dirty--
+	}
+	atomic.AddUint64(&l.bytes, ^(s.byteSize() - 1))
+	if l.bounds == nil {
+		return
+	}
+	for i, n := uint32(0), s.length(); i < n; i++ {
+		if e := s.entry(i); e != nil {
+			l.bounds.release(e.size)
+		}
+	}
+}
+
+// remove discards a single entry from a segment, returning false if it was
+// already removed. The caller must hold the Log's head lock
+func (l *Log[Msg]) remove(s *segment[Msg], i uint32) bool {
+	e := s.remove(i)
+	if e == nil {
+		return false
+	}
+	atomic.AddUint64(&l.bytes, ^(e.size - 1))
+	if l.bounds != nil {
+		l.bounds.release(e.size)
+	}
+	if l.store != nil && !s.dirty {
+		s.dirty = true
+		l.dirty++
+	}
+	return true
+}
+
+// flush rewrites the segment files of any full segments that have had
+// entries removed. The caller must hold the Log's head lock
+func (l *Log[Msg]) flush() {
+	if l.dirty == 0 {
+		return
+	}
+	base := retention.Offset(l.startOffset)
+	for curr := l.head.segment; curr != nil && !curr.isActive(); {
+		if curr.dirty {
+			if err := l.store.rewrite(base, curr); err != nil {
+				reportError(err)
+			} else {
+				curr.dirty = false
+				l.dirty--
+			}
+		}
+		base += retention.Offset(curr.cap)
+		curr = curr.getNext()
+	}
+}
+
+func (l *Log[_]) discard(base uint64) {
+	if l.store != nil {
+		if err := l.store.remove(retention.Offset(base)); err != nil {
+			reportError(err)
+		}
+	}
+}
+
+func (l *Log[_]) close() error {
+	if l.store != nil {
+		return l.store.close()
+	}
+	return nil
+}
+
 func (s *segment[Msg]) getNext() *segment[Msg] {
 	s.Lock()
 	defer s.Unlock()
@@ -176,11 +439,18 @@ func (s *segment[Msg]) append(entry *logEntry[Msg]) *segment[Msg] {
 		s.DisableLock()
 		return s.next.append(entry)
 	}
-	s.entries[s.len] = entry
+	s.entries[s.len].Store(entry)
+	atomic.AddUint64(&s.bytes, entry.size)
 	atomic.AddUint32(&s.len, uint32(1))
 	return s
 }
 
+// byteSize returns the total size in bytes of the entries that remain in the
+// segment
+func (s *segment[_]) byteSize() uint64 {
+	return atomic.LoadUint64(&s.bytes)
+}
+
 func (s *segment[_]) length() uint32 {
 	return atomic.LoadUint32(&s.len)
 }
@@ -193,8 +463,45 @@ func (s *segment[_]) isFull() bool {
 	return s.length() == s.cap
 }
 
-func (s *segment[_]) timeRange() (time.Time, time.Time) {
-	f := s.entries[0].createdAt
-	l := s.entries[s.len-1].createdAt
-	return f, l
+func (s *segment[Msg]) entry(i uint32) *logEntry[Msg] {
+	return s.entries[i].Load()
+}
+
+// remove discards the entry at the specified position, leaving its slot empty
+// so that the Offsets of the remaining entries are preserved
+func (s *segment[Msg]) remove(i uint32) *logEntry[Msg] {
+	e := s.entries[i].Swap(nil)
+	if e != nil {
+		atomic.AddUint32(&s.removed, uint32(1))
+		atomic.AddUint64(&s.bytes, ^(e.size - 1))
+	}
+	return e
+}
+
+// isCompactedAway returns whether every entry of a full segment has been
+// removed by compaction
+func (s *segment[_]) isCompactedAway() bool {
+	return atomic.LoadUint32(&s.removed) == s.cap
+}
+
+// timeRange returns the creation Times of the first and last entries that
+// remain in the segment, if there are any
+func (s *segment[Msg]) timeRange() (time.Time, time.Time, bool) {
+	n := s.length()
+	var first *logEntry[Msg]
+	i := uint32(0)
+	for ; i < n && first == nil; i++ {
+		first = s.entry(i)
+	}
+	if first == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	last := first
+	for j := n; j > i; j-- {
+		if e := s.entry(j - 1); e != nil {
+			last = e
+			break
+		}
+	}
+	return first.createdAt, last.createdAt, true
 }
@@ -0,0 +1,338 @@
+package topic
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/internal/sync/channel"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/partition"
+	"github.com/caravan/essentials/topic/retention"
+)
+
+type (
+	// Partitioned is the internal implementation of a PartitionedTopic
+	Partitioned[Msg any] struct {
+		closer.Closer
+		partitions  []*Topic[Msg]
+		partitioner partition.Partitioner
+		producers   *producers
+		backoff     backoff.Generator
+	}
+
+	keyedProducer[Msg any] struct {
+		closer.Closer
+		id      id.ID
+		channel chan topic.Keyed[Msg]
+	}
+
+	// mergedConsumer is a Consumer that owns a cursor for every partition
+	// of a PartitionedTopic, taking turns receiving from each of them
+	mergedConsumer[Msg any] struct {
+		closer.Closer
+		id      id.ID
+		cursors []*cursor[Msg]
+		ready   *channel.ReadyWait
+		channel chan Msg
+		seeks   chan topic.Position
+	}
+)
+
+// MakePartitioned instantiates a new internal PartitionedTopic instance with
+// the specified number of partitions. If the Topic is persistent, each
+// partition is stored in its own numbered subdirectory
+func MakePartitioned[Msg any](
+	partitions int, o ...config.Option,
+) topic.PartitionedTopic[Msg] {
+	res, err := OpenPartitioned[Msg](partitions, o...)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// OpenPartitioned instantiates a new internal PartitionedTopic instance,
+// returning an error rather than panicking if the Options are invalid or a
+// partition's storage can't be opened. Any partitions that were already
+// opened are closed before the error is returned
+func OpenPartitioned[Msg any](
+	partitions int, o ...config.Option,
+) (*Partitioned[Msg], error) {
+	if partitions < 1 {
+		return nil, errors.New(topic.ErrInvalidPartitionCount)
+	}
+	cfg, err := applyConfig(o...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Partitioned[Msg]{
+		partitions:  make([]*Topic[Msg], partitions),
+		partitioner: cfg.Partitioner,
+		producers:   makeProducers(),
+		backoff:     cfg.BackoffGenerator,
+	}
+	for i := range res.partitions {
+		pc := *cfg
+		if pc.StoragePath != "" {
+			pc.StoragePath = filepath.Join(cfg.StoragePath, strconv.Itoa(i))
+		}
+		p, err := openTopic[Msg](&pc)
+		if err != nil {
+			closeTopics(res.partitions[:i])
+			return nil, err
+		}
+		res.partitions[i] = p
+	}
+	res.Closer = makeCloser(func() {
+		closeTopics(res.partitions)
+	})
+	return res, nil
+}
+
+func closeTopics[Msg any](topics []*Topic[Msg]) {
+	for _, t := range topics {
+		t.Close()
+	}
+}
+
+// Close closes the PartitionedTopic and all of its partitions
+func (t *Partitioned[_]) Close() {
+	t.producers.close()
+	t.Closer.Close()
+}
+
+// Length returns the combined virtual size of all partitions
+func (t *Partitioned[_]) Length() topic.Length {
+	var res topic.Length
+	for _, p := range t.partitions {
+		res += p.Length()
+	}
+	return res
+}
+
+// Partitions returns the partitions of this PartitionedTopic
+func (t *Partitioned[Msg]) Partitions() []topic.Topic[Msg] {
+	res := make([]topic.Topic[Msg], len(t.partitions))
+	for i, p := range t.partitions {
+		res[i] = p
+	}
+	return res
+}
+
+// Put routes the specified Message to a partition based on its Key
+func (t *Partitioned[Msg]) Put(key topic.Key, msg Msg) error {
+	return t.put(key, msg, id.Nil, nil)
+}
+
+func (t *Partitioned[Msg]) put(
+	key topic.Key, msg Msg, pID id.ID, cancel <-chan struct{},
+) error {
+	e := &logEntry[Msg]{
+		msg:      msg,
+		producer: pID,
+	}
+	if key != nil {
+		e.meta = &metadata{key: key}
+	}
+	_, err := t.partitionFor(key).put(e, cancel)
+	return ignoreDropped(err)
+}
+
+func (t *Partitioned[Msg]) partitionFor(key topic.Key) *Topic[Msg] {
+	n := len(t.partitions)
+	i := t.partitioner(key, n) % n
+	if i < 0 {
+		i += n
+	}
+	return t.partitions[i]
+}
+
+// NewProducer instantiates a new PartitionedTopic KeyedProducer
+func (t *Partitioned[Msg]) NewProducer() topic.KeyedProducer[Msg] {
+	pID := id.New()
+	put := func(
+		e topic.Keyed[Msg], cancel <-chan struct{},
+	) (retention.Offset, error) {
+		return 0, t.put(e.Key, e.Message, pID, cancel)
+	}
+	var c closer.Closer
+	ch, stop := startProducer(put, nil, func(error) {
+		go c.Close()
+	})
+	c = makeCloser(func() {
+		stop()
+		t.producers.remove(pID)
+	})
+	if !t.producers.track(pID, c) {
+		c.Close()
+	}
+	return &keyedProducer[Msg]{
+		Closer:  c,
+		id:      pID,
+		channel: ch,
+	}
+}
+
+// NewConsumer instantiates a new Consumer that receives messages from all of
+// the PartitionedTopic's partitions, or returns an error if it's asked to join
+// a consumer group
+func (t *Partitioned[Msg]) NewConsumer(
+	o ...topic.ConsumerOption,
+) (topic.Consumer[Msg], error) {
+	cfg := topic.ApplyConsumerOptions(o...)
+	if cfg.Group != "" {
+		return nil, errors.New(topic.ErrPartitionedGroup)
+	}
+
+	res := makeMergedConsumer(t.partitions, cfg.Position)
+	go res.start(t.backoff)
+	return res, nil
+}
+
+// makeMergedConsumer creates a mergedConsumer with a cursor for each of the
+// specified Topics, positioned independently within each of them
+func makeMergedConsumer[Msg any](
+	topics []*Topic[Msg], p topic.Position,
+) *mergedConsumer[Msg] {
+	ready := channel.MakeReadyWait()
+	cursors := make([]*cursor[Msg], len(topics))
+	for i, t := range topics {
+		c := makeCursor(t, p)
+		t.cursors.track(c)
+		t.observers.add(c.id, ready.Notify)
+		cursors[i] = c
+	}
+	ready.Notify()
+
+	return &mergedConsumer[Msg]{
+		id:      id.New(),
+		cursors: cursors,
+		ready:   ready,
+		channel: make(chan Msg),
+		seeks:   make(chan topic.Position),
+		Closer: makeCloser(func() {
+			for _, c := range cursors {
+				c.Close()
+			}
+		}),
+	}
+}
+
+func (p *keyedProducer[_]) ID() id.ID {
+	return p.id
+}
+
+func (p *keyedProducer[Msg]) Send() chan<- topic.Keyed[Msg] {
+	return p.channel
+}
+
+func (c *mergedConsumer[_]) ID() id.ID {
+	return c.id
+}
+
+func (c *mergedConsumer[Msg]) Receive() <-chan Msg {
+	return c.channel
+}
+
+// ReceiveBatch returns up to max messages merged from the partitions
+func (c *mergedConsumer[Msg]) ReceiveBatch(
+	max int, wait time.Duration,
+) ([]Msg, bool) {
+	return receiveBatch(c.cursors[0].topic.Clock, c.channel, max, wait)
+}
+
+// Seek repositions the Consumer within each partition, resolving the Position
+// against each of them independently
+func (c *mergedConsumer[Msg]) Seek(p topic.Position) {
+	offsets := make(map[topic.Locator]topic.Offset, len(c.cursors))
+	for _, cur := range c.cursors {
+		offsets[cur.topic] = p(cur.topic)
+	}
+	p = func(l topic.Locator) topic.Offset {
+		return offsets[l]
+	}
+	select {
+	case <-c.IsClosed():
+	case c.seeks <- p:
+	}
+}
+
+func (c *mergedConsumer[Msg]) start(b backoff.Generator) {
+	defer close(c.channel)
+	timer := channel.MakeTimer(c.cursors[0].topic.Clock.NewTimer)
+	defer timer.Stop()
+	next := b()
+	turn := 0
+	for !closer.IsClosed(c) {
+		if i, e, ok := c.head(turn); ok {
+			select {
+			case <-c.IsClosed():
+				return
+			case p := <-c.seeks:
+				c.seek(p)
+				next = b()
+			case <-timer.Reset(next()):
+				// allow retention policies to kick in while waiting
+				// for a channel read to happen
+			case c.channel <- e:
+				c.cursors[i].advance()
+				turn = i + 1
+				next = b()
+			}
+			continue
+		}
+
+		if c.drained() {
+			c.Close()
+			return
+		}
+
+		select {
+		case <-c.IsClosed():
+			return
+		case p := <-c.seeks:
+			c.seek(p)
+			next = b()
+		case <-timer.Reset(next()):
+		case <-c.ready.Wait():
+		}
+	}
+}
+
+// head returns the first available message, checking each partition in turn
+// starting with the specified one, so that no partition is starved
+func (c *mergedConsumer[Msg]) head(turn int) (int, Msg, bool) {
+	n := len(c.cursors)
+	for j := 0; j < n; j++ {
+		i := (turn + j) % n
+		if e, ok := c.cursors[i].head(); ok {
+			return i, e, true
+		}
+	}
+	var zero Msg
+	return 0, zero, false
+}
+
+func (c *mergedConsumer[_]) seek(p topic.Position) {
+	for _, cur := range c.cursors {
+		cur.seek(p)
+	}
+}
+
+// drained returns whether every partition is closed and has been drained, in
+// which case nothing more will become available
+func (c *mergedConsumer[_]) drained() bool {
+	for _, cur := range c.cursors {
+		if !cur.drained() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+package topic_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/partition"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+type keyedValue struct {
+	key   string
+	value int
+}
+
+func TestPartitionedKeyOrdering(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePartitioned[keyedValue](4, config.Permanent)
+	as.Equal(4, len(top.Partitions()))
+
+	p := top.NewProducer()
+	keys := []string{"red", "green", "blue", "yellow", "purple"}
+	for i := 0; i < 100; i++ {
+		k := keys[i%len(keys)]
+		p.Send() <- topic.Keyed[keyedValue]{
+			Key:     topic.StringKey(k),
+			Message: keyedValue{k, i},
+		}
+	}
+	p.Close()
+	as.Equal(topic.Length(100), top.Length())
+
+	c, err := top.NewConsumer()
+	as.Nil(err)
+	last := map[string]int{}
+	for i := 0; i < 100; i++ {
+		e := message.MustReceive[keyedValue](c)
+		if prev, ok := last[e.key]; ok {
+			as.Less(prev, e.value)
+		}
+		last[e.key] = e.value
+	}
+	as.Equal(len(keys), len(last))
+	_, ok := message.Poll[keyedValue](c, 10*time.Millisecond)
+	as.False(ok)
+	c.Close()
+
+	// every message with the same Key lands in the same partition
+	for _, part := range top.Partitions() {
+		pc := part.NewConsumer()
+		seen := map[string]bool{}
+		for {
+			e, ok := message.Poll[keyedValue](pc, 10*time.Millisecond)
+			if !ok {
+				break
+			}
+			seen[e.key] = true
+		}
+		for k := range seen {
+			idx := partition.Hash(topic.StringKey(k), 4)
+			as.Equal(part, top.Partitions()[idx])
+		}
+		pc.Close()
+	}
+	top.Close()
+}
+
+func TestPartitionedRoundRobin(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePartitioned[int](3, config.RoundRobin)
+	l := top.(*internal.Partitioned[int])
+	for i := 0; i < 9; i++ {
+		as.Nil(l.Put(nil, i))
+	}
+	for _, part := range top.Partitions() {
+		as.Equal(topic.Length(3), part.Length())
+	}
+
+	c := top.Partitions()[1].NewConsumer()
+	as.Equal(1, message.MustReceive[int](c))
+	as.Equal(4, message.MustReceive[int](c))
+	c.Close()
+	top.Close()
+}
+
+func TestPartitionedCustomPartitioner(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePartitioned[string](2,
+		config.Partitioner(func(k topic.Key, _ int) int {
+			return -len(k.Bytes())
+		}),
+	)
+	l := top.(*internal.Partitioned[string])
+	as.Nil(l.Put(topic.StringKey("a"), "odd"))
+	as.Nil(l.Put(topic.StringKey("ab"), "even"))
+	as.Equal(topic.Length(1), top.Partitions()[0].Length())
+	as.Equal(topic.Length(1), top.Partitions()[1].Length())
+	top.Close()
+}
+
+func TestPartitionedClose(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePartitioned[string](3)
+	p := top.NewProducer()
+	c, err := top.NewConsumer()
+	as.Nil(err)
+	for i := 0; i < 6; i++ {
+		p.Send() <- topic.Keyed[string]{
+			Key:     topic.StringKey(fmt.Sprint(i)),
+			Message: fmt.Sprint(i),
+		}
+	}
+	top.Close()
+	as.True(closer.IsClosed(p))
+	for _, part := range top.Partitions() {
+		as.True(closer.IsClosed(part))
+	}
+	as.True(closer.IsClosed(top.NewProducer()))
+
+	var res []string
+	for e := range c.Receive() {
+		res = append(res, e)
+	}
+	as.ElementsMatch([]string{"0", "1", "2", "3", "4", "5"}, res)
+}
+
+func TestPartitionedSeek(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePartitioned[int](2, config.RoundRobin)
+	l := top.(*internal.Partitioned[int])
+	for i := 0; i < 4; i++ {
+		as.Nil(l.Put(nil, i))
+	}
+
+	c, err := top.NewConsumer(topic.StartAt(topic.Latest))
+	as.Nil(err)
+	_, ok := message.Poll[int](c, 10*time.Millisecond)
+	as.False(ok)
+
+	c.Seek(topic.AtOffset(1))
+	as.ElementsMatch([]int{2, 3}, []int{
+		message.MustReceive[int](c), message.MustReceive[int](c),
+	})
+	c.Close()
+	top.Close()
+}
+
+func TestPartitionedErrors(t *testing.T) {
+	as := assert.New(t)
+	func() {
+		defer func() {
+			as.EqualError(recover().(error), topic.ErrInvalidPartitionCount)
+		}()
+		internal.MakePartitioned[int](0)
+	}()
+
+	top := internal.MakePartitioned[int](2)
+	c, err := top.NewConsumer(topic.Group("workers"))
+	as.Nil(c)
+	as.EqualError(err, topic.ErrPartitionedGroup)
+	top.Close()
+}
+
+func TestPartitionedOpen(t *testing.T) {
+	as := assert.New(t)
+	top, err := essentials.OpenPartitionedTopic[int](0)
+	as.Nil(top)
+	as.EqualError(err, topic.ErrInvalidPartitionCount)
+
+	top, err = essentials.OpenPartitionedTopic[int](2, config.TTL(-1))
+	as.Nil(top)
+	as.EqualError(err, config.ErrInvalidTTL)
+}
+
+func TestPartitionedCorruptSegment(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.MakePartitioned[int](2,
+		config.Persistent(dir), config.RoundRobin,
+	)
+	l := top.(*internal.Partitioned[int])
+	for i := 0; i < segmentSize*4; i++ {
+		as.Nil(l.Put(nil, i))
+	}
+	top.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "1", "*.seg"))
+	as.Nil(os.Truncate(files[0], 20))
+
+	// the first partition is opened before the second fails, and is closed
+	res, err := essentials.OpenPartitionedTopic[int](2, config.Persistent(dir))
+	as.Nil(res)
+	as.Error(err)
+}
+
+func TestPartitionedPersistent(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+
+	top := internal.MakePartitioned[string](2,
+		config.Persistent(dir), config.RoundRobin,
+	)
+	l := top.(*internal.Partitioned[string])
+	as.Nil(l.Put(nil, "first"))
+	as.Nil(l.Put(nil, "second"))
+	top.Close()
+
+	top = internal.MakePartitioned[string](2, config.Persistent(dir))
+	parts := top.Partitions()
+	c0 := parts[0].NewConsumer()
+	c1 := parts[1].NewConsumer()
+	as.Equal("first", message.MustReceive[string](c0))
+	as.Equal("second", message.MustReceive[string](c1))
+	c0.Close()
+	c1.Close()
+	top.Close()
+}
@@ -0,0 +1,265 @@
+package topic
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/internal/sync/channel"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/priority"
+	"github.com/caravan/essentials/topic/retention"
+)
+
+type (
+	// Prioritized is the internal implementation of a PriorityTopic
+	Prioritized[Msg any] struct {
+		closer.Closer
+		levels    []*Topic[Msg]
+		scheduler priority.Scheduler
+		producers *producers
+		backoff   backoff.Generator
+	}
+
+	priorityProducer[Msg any] struct {
+		closer.Closer
+		id      id.ID
+		channel chan topic.Prioritized[Msg]
+	}
+
+	// priorityConsumer is a mergedConsumer that lets a priority Selector
+	// choose which of its levels is delivered from next
+	priorityConsumer[Msg any] struct {
+		*mergedConsumer[Msg]
+		heads []priority.Head
+	}
+)
+
+// MakePriority instantiates a new internal PriorityTopic instance with the
+// specified number of priority levels. If the Topic is persistent, each level
+// is stored in its own numbered subdirectory
+func MakePriority[Msg any](
+	levels int, o ...config.Option,
+) topic.PriorityTopic[Msg] {
+	res, err := OpenPriority[Msg](levels, o...)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// OpenPriority instantiates a new internal PriorityTopic instance, returning
+// an error rather than panicking if the Options are invalid or a level's
+// storage can't be opened. Any levels that were already opened are closed
+// before the error is returned
+func OpenPriority[Msg any](
+	levels int, o ...config.Option,
+) (*Prioritized[Msg], error) {
+	if levels < 1 {
+		return nil, errors.New(topic.ErrInvalidPriorityLevels)
+	}
+	cfg, err := applyConfig(o...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Prioritized[Msg]{
+		levels:    make([]*Topic[Msg], levels),
+		scheduler: cfg.Scheduler,
+		producers: makeProducers(),
+		backoff:   cfg.BackoffGenerator,
+	}
+	for i := range res.levels {
+		lc := *cfg
+		if lc.StoragePath != "" {
+			lc.StoragePath = filepath.Join(cfg.StoragePath, strconv.Itoa(i))
+		}
+		l, err := openTopic[Msg](&lc)
+		if err != nil {
+			closeTopics(res.levels[:i])
+			return nil, err
+		}
+		res.levels[i] = l
+	}
+	res.Closer = makeCloser(func() {
+		closeTopics(res.levels)
+	})
+	return res, nil
+}
+
+// Close closes the PriorityTopic and all of its levels
+func (t *Prioritized[_]) Close() {
+	t.producers.close()
+	t.Closer.Close()
+}
+
+// Length returns the combined virtual size of all levels
+func (t *Prioritized[_]) Length() topic.Length {
+	var res topic.Length
+	for _, l := range t.levels {
+		res += l.Length()
+	}
+	return res
+}
+
+// Levels returns the levels of this PriorityTopic, from highest to lowest
+// priority
+func (t *Prioritized[Msg]) Levels() []topic.Topic[Msg] {
+	res := make([]topic.Topic[Msg], len(t.levels))
+	for i, l := range t.levels {
+		res[i] = l
+	}
+	return res
+}
+
+// Put adds the specified Message to the level of the specified Priority
+func (t *Prioritized[Msg]) Put(p topic.Priority, msg Msg) error {
+	return t.put(p, msg, id.Nil, nil)
+}
+
+func (t *Prioritized[Msg]) put(
+	p topic.Priority, msg Msg, pID id.ID, cancel <-chan struct{},
+) error {
+	if p < 0 || int(p) >= len(t.levels) {
+		return errors.New(topic.ErrInvalidPriority)
+	}
+	_, err := t.levels[p].put(&logEntry[Msg]{
+		msg:      msg,
+		producer: pID,
+	}, cancel)
+	return ignoreDropped(err)
+}
+
+// NewProducer instantiates a new PriorityTopic PriorityProducer
+func (t *Prioritized[Msg]) NewProducer() topic.PriorityProducer[Msg] {
+	pID := id.New()
+	put := func(
+		e topic.Prioritized[Msg], cancel <-chan struct{},
+	) (retention.Offset, error) {
+		return 0, t.put(e.Priority, e.Message, pID, cancel)
+	}
+	var c closer.Closer
+	ch, stop := startProducer(put, nil, func(error) {
+		go c.Close()
+	})
+	c = makeCloser(func() {
+		stop()
+		t.producers.remove(pID)
+	})
+	if !t.producers.track(pID, c) {
+		c.Close()
+	}
+	return &priorityProducer[Msg]{
+		Closer:  c,
+		id:      pID,
+		channel: ch,
+	}
+}
+
+// NewConsumer instantiates a new Consumer that receives messages from all of
+// the PriorityTopic's levels, as directed by its priority Scheduler, or
+// returns an error if it's asked to join a consumer group
+func (t *Prioritized[Msg]) NewConsumer(
+	o ...topic.ConsumerOption,
+) (topic.Consumer[Msg], error) {
+	cfg := topic.ApplyConsumerOptions(o...)
+	if cfg.Group != "" {
+		return nil, errors.New(topic.ErrPriorityGroup)
+	}
+
+	res := &priorityConsumer[Msg]{
+		mergedConsumer: makeMergedConsumer(t.levels, cfg.Position),
+		heads:          make([]priority.Head, 0, len(t.levels)),
+	}
+	go res.start(t.backoff, t.scheduler())
+	return res, nil
+}
+
+func (p *priorityProducer[_]) ID() id.ID {
+	return p.id
+}
+
+func (p *priorityProducer[Msg]) Send() chan<- topic.Prioritized[Msg] {
+	return p.channel
+}
+
+// start delivers messages from the level that the Selector chooses. That
+// level is kept until its message is delivered, unless the message stops
+// being available or the Consumer seeks, in which case a level is chosen anew
+func (c *priorityConsumer[Msg]) start(
+	b backoff.Generator, sel priority.Selector,
+) {
+	defer close(c.channel)
+	timer := channel.MakeTimer(c.cursors[0].topic.Clock.NewTimer)
+	defer timer.Stop()
+	next := b()
+	chosen := -1
+	for !closer.IsClosed(c) {
+		if chosen == -1 {
+			chosen = c.choose(sel)
+		}
+		if chosen != -1 {
+			cur := c.cursors[chosen]
+			e, ok := cur.head()
+			if !ok {
+				chosen = -1
+				continue
+			}
+			select {
+			case <-c.IsClosed():
+				return
+			case p := <-c.seeks:
+				c.seek(p)
+				chosen = -1
+				next = b()
+			case <-timer.Reset(next()):
+				// allow retention policies to kick in while waiting
+				// for a channel read to happen
+			case c.channel <- e:
+				cur.advance()
+				chosen = -1
+				next = b()
+			}
+			continue
+		}
+
+		if c.drained() {
+			c.Close()
+			return
+		}
+
+		select {
+		case <-c.IsClosed():
+			return
+		case p := <-c.seeks:
+			c.seek(p)
+			next = b()
+		case <-timer.Reset(next()):
+		case <-c.ready.Wait():
+		}
+	}
+}
+
+// choose returns the level that the Selector chooses from among those with an
+// available message, or -1 if none of them have one
+func (c *priorityConsumer[Msg]) choose(sel priority.Selector) int {
+	heads := c.heads[:0]
+	for i, cur := range c.cursors {
+		if e, ok := cur.entry(); ok {
+			heads = append(heads, priority.Head{
+				Priority:   topic.Priority(i),
+				ProducedAt: e.createdAt,
+			})
+		}
+	}
+	c.heads = heads
+	if len(heads) == 0 {
+		return -1
+	}
+	now := c.cursors[0].topic.Clock.Now()
+	return int(heads[sel(now, heads)].Priority)
+}
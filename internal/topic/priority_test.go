@@ -0,0 +1,189 @@
+package topic_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/clock/clocktest"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+func TestPriorityStrict(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePriority[string](3)
+	as.Equal(3, len(top.Levels()))
+
+	p := top.NewProducer()
+	p.Send() <- topic.Prioritized[string]{Priority: 2, Message: "low 1"}
+	p.Send() <- topic.Prioritized[string]{Priority: 2, Message: "low 2"}
+	p.Send() <- topic.Prioritized[string]{Priority: 1, Message: "normal"}
+	p.Send() <- topic.Prioritized[string]{Priority: 0, Message: "urgent"}
+	p.Close()
+	as.Equal(topic.Length(4), top.Length())
+
+	c, err := top.NewConsumer()
+	as.Nil(err)
+	as.Equal("urgent", message.MustReceive[string](c))
+	as.Equal("normal", message.MustReceive[string](c))
+	as.Equal("low 1", message.MustReceive[string](c))
+	as.Equal("low 2", message.MustReceive[string](c))
+	_, ok := message.Poll[string](c, 10*time.Millisecond)
+	as.False(ok)
+
+	// messages arriving later are still delivered by priority
+	l := top.(*internal.Prioritized[string])
+	as.Nil(l.Put(1, "later"))
+	as.Equal("later", message.MustReceive[string](c))
+
+	c.Seek(topic.Earliest)
+	as.Equal("urgent", message.MustReceive[string](c))
+	c.Close()
+	top.Close()
+}
+
+func TestPriorityWeightedFair(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePriority[int](2, config.WeightedFair(3, 1))
+	l := top.(*internal.Prioritized[int])
+	for i := 0; i < 8; i++ {
+		as.Nil(l.Put(1, 100+i))
+		as.Nil(l.Put(0, i))
+	}
+
+	c, err := top.NewConsumer()
+	as.Nil(err)
+	var res []int
+	for i := 0; i < 8; i++ {
+		res = append(res, message.MustReceive[int](c))
+	}
+	as.Equal([]int{0, 1, 100, 2, 3, 4, 101, 5}, res)
+	c.Close()
+	top.Close()
+}
+
+func TestPriorityAging(t *testing.T) {
+	as := assert.New(t)
+	clk := clocktest.Make(time.Unix(0, 0))
+	top := internal.MakePriority[string](3,
+		config.Aging(time.Second), config.Clock(clk),
+	)
+	l := top.(*internal.Prioritized[string])
+	as.Nil(l.Put(2, "old"))
+	clk.Advance(3 * time.Second)
+	as.Nil(l.Put(0, "new"))
+	as.Nil(l.Put(1, "newer"))
+
+	// "old" has been promoted past the highest priority level
+	c, err := top.NewConsumer()
+	as.Nil(err)
+	as.Equal("old", message.MustReceive[string](c))
+	as.Equal("new", message.MustReceive[string](c))
+	as.Equal("newer", message.MustReceive[string](c))
+	c.Close()
+	top.Close()
+}
+
+func TestPriorityInvalid(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePriority[string](2)
+	l := top.(*internal.Prioritized[string])
+	as.EqualError(l.Put(2, "missing"), topic.ErrInvalidPriority)
+	as.EqualError(l.Put(-1, "missing"), topic.ErrInvalidPriority)
+	as.Equal(topic.Length(0), top.Length())
+
+	c, err := top.NewConsumer(topic.Group("group"))
+	as.Nil(c)
+	as.EqualError(err, topic.ErrPriorityGroup)
+	top.Close()
+}
+
+func TestPriorityLevelCount(t *testing.T) {
+	as := assert.New(t)
+	defer func() {
+		as.EqualError(recover().(error), topic.ErrInvalidPriorityLevels)
+	}()
+	internal.MakePriority[string](0)
+}
+
+func TestPriorityOpen(t *testing.T) {
+	as := assert.New(t)
+	top, err := essentials.OpenPriorityTopic[int](0)
+	as.Nil(top)
+	as.EqualError(err, topic.ErrInvalidPriorityLevels)
+
+	top, err = essentials.OpenPriorityTopic[int](2, config.TTL(-1))
+	as.Nil(top)
+	as.EqualError(err, config.ErrInvalidTTL)
+}
+
+func TestPriorityCorruptSegment(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.MakePriority[int](2, config.Persistent(dir))
+	l := top.(*internal.Prioritized[int])
+	for i := 0; i < segmentSize*4; i++ {
+		as.Nil(l.Put(1, i))
+	}
+	top.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "1", "*.seg"))
+	as.Nil(os.Truncate(files[0], 20))
+
+	// the first level is opened before the second fails, and is closed
+	res, err := essentials.OpenPriorityTopic[int](2, config.Persistent(dir))
+	as.Nil(res)
+	as.Error(err)
+}
+
+func TestPriorityClose(t *testing.T) {
+	as := assert.New(t)
+	top := internal.MakePriority[string](2)
+	l := top.(*internal.Prioritized[string])
+	p := top.NewProducer()
+	as.Nil(l.Put(1, "low"))
+	as.Nil(l.Put(0, "high"))
+	c, err := top.NewConsumer()
+	as.Nil(err)
+	top.Close()
+	as.True(closer.IsClosed(p))
+	for _, l := range top.Levels() {
+		as.True(closer.IsClosed(l))
+	}
+
+	as.Equal("high", message.MustReceive[string](c))
+	as.Equal("low", message.MustReceive[string](c))
+	_, ok := <-c.Receive()
+	as.False(ok)
+	as.True(closer.IsClosed(c))
+}
+
+func TestPersistentPriority(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	top := internal.MakePriority[string](2, config.Persistent(dir))
+	l := top.(*internal.Prioritized[string])
+	as.Nil(l.Put(1, "low"))
+	as.Nil(l.Put(0, "high"))
+	top.Close()
+	as.DirExists(filepath.Join(dir, "0"))
+	as.DirExists(filepath.Join(dir, "1"))
+
+	top = internal.MakePriority[string](2, config.Persistent(dir))
+	c, err := top.NewConsumer()
+	as.Nil(err)
+	as.Equal("high", message.MustReceive[string](c))
+	as.Equal("low", message.MustReceive[string](c))
+	c.Close()
+	top.Close()
+}
@@ -1,32 +1,76 @@
 package topic
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
+	"sync"
 
 	"github.com/caravan/essentials/closer"
 	"github.com/caravan/essentials/id"
 	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/retention"
 )
 
-type producer[Msg any] struct {
-	closer.Closer
-	id      id.ID
-	topic   *Topic[Msg]
-	channel chan Msg
-}
+type (
+	producer[Msg any] struct {
+		closer.Closer
+		id       id.ID
+		topic    *Topic[Msg]
+		channel  chan Msg
+		requests chan func(<-chan struct{})
+		failure  *failure
+	}
+
+	// failure records the storage error that closed a Producer, so that it
+	// can be returned by the Producer's subsequent synchronous calls
+	failure struct {
+		sync.Mutex
+		err error
+	}
+
+	// producers manages the Closers of a Topic's outstanding Producers.
+	// Only the Closers are tracked so that abandoned Producers can still
+	// be finalized
+	producers struct {
+		sync.Mutex
+		closers map[id.ID]closer.Closer
+		closed  bool
+	}
+)
 
 func makeProducer[Msg any](t *Topic[Msg]) *producer[Msg] {
-	ch := startProducer(t)
+	pID := id.New()
+	requests := make(chan func(<-chan struct{}))
+	put := func(msg Msg, cancel <-chan struct{}) (retention.Offset, error) {
+		return t.put(&logEntry[Msg]{
+			msg:      msg,
+			producer: pID,
+		}, cancel)
+	}
+	f := &failure{}
+	var c closer.Closer
+	ch, stop := startProducer(put, requests, func(err error) {
+		if f.set(err) {
+			go c.Close()
+		}
+	})
+	c = makeCloser(func() {
+		stop()
+		t.producers.remove(pID)
+	})
 	res := &producer[Msg]{
-		id:      id.New(),
-		topic:   t,
-		channel: ch,
-		Closer: makeCloser(func() {
-			close(ch)
-		}),
+		id:       pID,
+		topic:    t,
+		channel:  ch,
+		requests: requests,
+		failure:  f,
+		Closer:   c,
+	}
+	if !t.producers.track(pID, c) {
+		c.Close()
+		return res
 	}
-
 	if Debug.IsEnabled() {
 		wrap := WrapStackTrace(MsgInstantiationTrace)
 		runtime.SetFinalizer(res, producerDebugFinalizer[Msg](wrap))
@@ -42,18 +86,149 @@ func (p *producer[Msg]) Send() chan<- Msg {
 	return p.channel
 }
 
-func startProducer[Msg any](t *Topic[Msg]) chan Msg {
+// Produce appends a message to the Topic, returning its assigned Offset
+func (p *producer[Msg]) Produce(msg Msg) (retention.Offset, error) {
+	res, err := p.ProduceBatch([]Msg{msg})
+	if err != nil {
+		return 0, err
+	}
+	return res[0], nil
+}
+
+// ProduceBatch appends messages to the Topic in order, returning their
+// assigned Offsets. If an error occurs, the Offsets assigned before it are
+// returned along with the error
+func (p *producer[Msg]) ProduceBatch(
+	msgs []Msg,
+) ([]retention.Offset, error) {
+	entries := make([]*logEntry[Msg], len(msgs))
+	for i, msg := range msgs {
+		entries[i] = &logEntry[Msg]{
+			msg:      msg,
+			producer: p.id,
+		}
+	}
+
+	var res []retention.Offset
+	var err error
+	done := make(chan struct{})
+	select {
+	case <-p.IsClosed():
+		if err := p.failure.get(); err != nil {
+			return nil, err
+		}
+		if closer.IsClosed(p.topic) {
+			return nil, errors.New(topic.ErrTopicClosed)
+		}
+		return nil, errors.New(topic.ErrProducerClosed)
+	case p.requests <- func(cancel <-chan struct{}) {
+		defer close(done)
+		res, err = p.topic.putBatch(entries, cancel)
+	}:
+		<-done
+		return res, err
+	}
+}
+
+// startProducer starts the routine that appends the messages sent to a
+// Producer's channel. Synchronous requests are performed by the same routine
+// so that they're ordered with those messages. A nil requests channel is
+// never read. The returned function stops the routine, canceling any put
+// that's waiting for room in a bounded Topic. If a sent message can't be
+// persisted, fail is called with the error so that the Producer can be closed
+func startProducer[Msg any](
+	put func(Msg, <-chan struct{}) (retention.Offset, error),
+	requests <-chan func(<-chan struct{}),
+	fail func(error),
+) (chan Msg, func()) {
 	ch := make(chan Msg)
+	cancel := make(chan struct{})
+	done := make(chan struct{})
 	go func() {
 		defer func() {
 			// probably because the channel was closed
 			recover()
+			close(done)
 		}()
-		for e := range ch {
-			t.Put(e)
+		for {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					return
+				}
+				_, err := put(e, cancel)
+				if ignoreDropped(err) != nil {
+					reportError(err)
+				}
+				if isWriteError(err) {
+					fail(err)
+				}
+			case r := <-requests:
+				r(cancel)
+			}
 		}
 	}()
-	return ch
+	return ch, func() {
+		close(cancel)
+		close(ch)
+		<-done
+	}
+}
+
+// set records the error that's closing a Producer, returning false if one
+// was already recorded
+func (f *failure) set(err error) bool {
+	f.Lock()
+	defer f.Unlock()
+	if f.err != nil {
+		return false
+	}
+	f.err = err
+	return true
+}
+
+func (f *failure) get() error {
+	f.Lock()
+	defer f.Unlock()
+	return f.err
+}
+
+func makeProducers() *producers {
+	return &producers{
+		closers: map[id.ID]closer.Closer{},
+	}
+}
+
+func (p *producers) track(i id.ID, c closer.Closer) bool {
+	p.Lock()
+	defer p.Unlock()
+	if p.closed {
+		return false
+	}
+	p.closers[i] = c
+	return true
+}
+
+func (p *producers) remove(i id.ID) {
+	p.Lock()
+	defer p.Unlock()
+	delete(p.closers, i)
+}
+
+// close closes all outstanding Producers and prevents any new Producers from
+// being tracked
+func (p *producers) close() {
+	p.Lock()
+	p.closed = true
+	closers := make([]closer.Closer, 0, len(p.closers))
+	for _, c := range p.closers {
+		closers = append(closers, c)
+	}
+	p.Unlock()
+
+	for _, c := range closers {
+		c.Close()
+	}
 }
 
 func producerDebugFinalizer[Msg any](
@@ -10,6 +10,7 @@ import (
 	"github.com/caravan/essentials/message"
 	"github.com/caravan/essentials/topic"
 	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/overflow"
 	"github.com/stretchr/testify/assert"
 
 	internal "github.com/caravan/essentials/internal/topic"
@@ -137,3 +138,77 @@ func TestProducerChannelClosed(t *testing.T) {
 
 	ch <- "hello"
 }
+
+func TestProduce(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[string]()
+	p := top.NewProducer()
+
+	o, err := p.Produce("first")
+	as.Nil(err)
+	as.Equal(topic.Offset(0), o)
+
+	p.Send() <- "second"
+	o, err = p.Produce("third")
+	as.Nil(err)
+	as.Equal(topic.Offset(2), o)
+
+	offsets, err := p.ProduceBatch([]string{"fourth", "fifth"})
+	as.Nil(err)
+	as.Equal([]topic.Offset{3, 4}, offsets)
+
+	// the produced message is immediately available
+	l := top.(*internal.Topic[string])
+	msg, _, ok := l.Get(o)
+	as.True(ok)
+	as.Equal("third", msg)
+
+	p.Close()
+	top.Close()
+}
+
+func TestProduceClosed(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[string]()
+	p := top.NewProducer()
+	p.Close()
+
+	_, err := p.Produce("closed")
+	as.EqualError(err, topic.ErrProducerClosed)
+
+	p = top.NewProducer()
+	top.Close()
+	offsets, err := p.ProduceBatch([]string{"closed"})
+	as.Nil(offsets)
+	as.EqualError(err, topic.ErrTopicClosed)
+}
+
+func TestProduceOverflow(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[int](
+		config.MaxMessages(2), config.Overflow(overflow.Fail),
+	)
+	p := top.NewProducer()
+	offsets, err := p.ProduceBatch([]int{0, 1, 2})
+	as.Equal([]topic.Offset{0, 1}, offsets)
+	as.EqualError(err, topic.ErrTopicFull)
+	p.Close()
+	top.Close()
+
+	top = internal.Make[int](
+		config.MaxMessages(1), config.Overflow(overflow.DropNewest),
+	)
+	p = top.NewProducer()
+	_, err = p.Produce(0)
+	as.Nil(err)
+	_, err = p.Produce(1)
+	as.EqualError(err, topic.ErrMessageDropped)
+	as.ErrorIs(err, topic.ErrDropped)
+	_, err = p.ProduceBatch([]int{2})
+	as.ErrorIs(err, topic.ErrDropped)
+	p.Close()
+	top.Close()
+}
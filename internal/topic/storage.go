@@ -0,0 +1,658 @@
+package topic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/retention"
+	"github.com/caravan/essentials/topic/storage"
+)
+
+type (
+	// store persists the entries of a Log to a chain of segment files that
+	// mirror the Log's in-memory segments. Each file is named for the
+	// Offset of its first entry
+	store[Msg any] struct {
+		sync.Mutex
+		path      string
+		codec     storage.Codec
+		sync      storage.SyncPolicy
+		capacity  uint32
+		file      *os.File
+		size      int64
+		base      retention.Offset
+		next      retention.Offset
+		remaining uint32
+		closed    bool
+	}
+
+	segmentFile struct {
+		base retention.Offset
+		path string
+	}
+
+	// writeError is returned when an entry can't be persisted to a segment
+	// file. It's distinguished so that a Producer can be closed by it
+	writeError struct {
+		err error
+	}
+)
+
+const (
+	segmentFileExt    = ".seg"
+	segmentTempExt    = ".tmp"
+	segmentHeaderSize = 8
+	recordHeaderSize  = 16
+
+	// removedRecordSize marks a record whose entry was removed by
+	// compaction. Such a record has no payload
+	removedRecordSize = math.MaxUint32
+
+	// recordMetaFlag is set in the size of a record whose payload is
+	// prefixed by the entry's producer and metadata
+	recordMetaFlag = 1 << 31
+)
+
+var (
+	segmentMagic = []byte("CRVN")
+
+	errHeader   = errors.New("invalid segment header")
+	errChecksum = errors.New("record checksum mismatch")
+)
+
+func openStore[Msg any](cfg *config.Config) (*store[Msg], error) {
+	if err := os.MkdirAll(cfg.StoragePath, 0o755); err != nil {
+		return nil, err
+	}
+	return &store[Msg]{
+		path:     cfg.StoragePath,
+		codec:    cfg.StorageCodec,
+		sync:     cfg.StorageSync,
+		capacity: uint32(cfg.SegmentIncrement),
+	}, nil
+}
+
+func writeFailed(err error) error {
+	return &writeError{err: err}
+}
+
+func (e *writeError) Error() string {
+	return fmt.Errorf(storage.ErrWriteFailed, e.err).Error()
+}
+
+func (e *writeError) Unwrap() error {
+	return e.err
+}
+
+func isWriteError(err error) bool {
+	var w *writeError
+	return errors.As(err, &w)
+}
+
+// restore rebuilds the Log's segment chain from the store's segment files,
+// leaving the last of those files open for appending
+func (l *Log[Msg]) restore(s *store[Msg]) error {
+	files, err := s.segmentFiles()
+	if err != nil {
+		return err
+	}
+
+	var tail *segment[Msg]
+	var expected retention.Offset
+	for i, f := range files {
+		seg, err := s.readSegment(l, f, i == len(files)-1)
+		if err != nil {
+			return err
+		}
+		if tail == nil {
+			l.startOffset = uint64(f.base)
+			l.head.segment = seg
+		} else {
+			if f.base != expected {
+				return fmt.Errorf(storage.ErrSegmentGap, f.path)
+			}
+			tail.next = seg
+			tail.DisableLock()
+		}
+		tail = seg
+		expected = f.base + retention.Offset(seg.cap)
+		l.restored(f.base, seg)
+	}
+
+	if tail != nil {
+		l.tail.segment = tail
+		l.virtualLength = uint64(s.next)
+	}
+	return nil
+}
+
+func (s *store[_]) segmentFiles() ([]segmentFile, error) {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var res []segmentFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentFileExt) {
+			continue
+		}
+		base, err := strconv.ParseUint(
+			strings.TrimSuffix(name, segmentFileExt), 10, 64,
+		)
+		if err != nil {
+			continue
+		}
+		res = append(res, segmentFile{
+			base: retention.Offset(base),
+			path: filepath.Join(s.path, name),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].base < res[j].base
+	})
+	return res, nil
+}
+
+// readSegment loads a segment file into a new in-memory segment. Only the last
+// segment file is allowed to be incomplete, in which case any torn record is
+// truncated and the file is kept open for appending
+func (s *store[Msg]) readSegment(
+	l *Log[Msg], f segmentFile, last bool,
+) (*segment[Msg], error) {
+	file, err := os.OpenFile(f.path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	capacity, err := readSegmentHeader(file)
+	if err != nil {
+		if !last || err == errHeader {
+			_ = file.Close()
+			return nil, fmt.Errorf(storage.ErrCorruptSegment, f.path)
+		}
+		// the segment file was created, but its header was never written
+		capacity = s.capacity
+		if err := resetSegmentFile(file, capacity); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+
+	seg := &segment[Msg]{
+		log:     l,
+		cap:     capacity,
+		entries: make([]atomic.Pointer[logEntry[Msg]], capacity),
+	}
+	size := int64(segmentHeaderSize)
+	r := bufio.NewReader(file)
+	for seg.len < seg.cap {
+		e, n, err := s.readRecord(r, f, info.Size()-size)
+		if err == io.EOF {
+			break
+		}
+		if last && (err == io.ErrUnexpectedEOF || err == errChecksum) {
+			break // torn record from an interrupted write
+		}
+		if err == io.ErrUnexpectedEOF || err == errChecksum {
+			err = fmt.Errorf(storage.ErrCorruptSegment, f.path)
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		if e != nil {
+			seg.entries[seg.len].Store(e)
+		} else {
+			seg.removed++
+		}
+		seg.len++
+		size += n
+	}
+
+	if !last {
+		_ = file.Close()
+		if !seg.isFull() {
+			return nil, fmt.Errorf(storage.ErrCorruptSegment, f.path)
+		}
+		return seg, nil
+	}
+
+	if err := file.Truncate(size); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	s.file = file
+	s.size = size
+	s.base = f.base
+	s.next = f.base + retention.Offset(seg.len)
+	s.remaining = seg.cap - seg.len
+	return seg, nil
+}
+
+func (s *store[Msg]) readRecord(
+	r io.Reader, f segmentFile, avail int64,
+) (*logEntry[Msg], int64, error) {
+	head := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, 0, err
+	}
+	size := binary.BigEndian.Uint32(head[0:])
+	sum := binary.BigEndian.Uint32(head[4:])
+	if size == removedRecordSize {
+		if crc32.ChecksumIEEE(head[8:]) != sum {
+			return nil, 0, errChecksum
+		}
+		return nil, recordHeaderSize, nil
+	}
+	hasMeta := size&recordMetaFlag != 0
+	size &^= recordMetaFlag
+	if int64(size) > avail-recordHeaderSize {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	crc := crc32.Update(crc32.ChecksumIEEE(head[8:]), crc32.IEEETable, data)
+	if crc != sum {
+		return nil, 0, errChecksum
+	}
+
+	res := &logEntry[Msg]{
+		createdAt: time.Unix(0, int64(binary.BigEndian.Uint64(head[8:]))),
+	}
+	if hasMeta {
+		var err error
+		if data, err = decodeMetadata(res, data); err != nil {
+			return nil, 0, fmt.Errorf(storage.ErrCorruptSegment, f.path)
+		}
+	}
+	if err := s.codec.Unmarshal(data, &res.msg); err != nil {
+		return nil, 0, fmt.Errorf(storage.ErrUnmarshalFailed, f.path, err)
+	}
+	return res, int64(recordHeaderSize) + int64(size), nil
+}
+
+func readSegmentHeader(file *os.File) (uint32, error) {
+	head := make([]byte, segmentHeaderSize)
+	if _, err := io.ReadFull(file, head); err != nil {
+		return 0, err
+	}
+	if string(head[:len(segmentMagic)]) != string(segmentMagic) {
+		return 0, errHeader
+	}
+	return binary.BigEndian.Uint32(head[len(segmentMagic):]), nil
+}
+
+func resetSegmentFile(file *os.File, capacity uint32) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	head := make([]byte, segmentHeaderSize)
+	copy(head, segmentMagic)
+	binary.BigEndian.PutUint32(head[len(segmentMagic):], capacity)
+	_, err := file.WriteAt(head, 0)
+	return err
+}
+
+// write appends an entry to the current segment file, starting a new segment
+// file if the current one is full
+func (s *store[Msg]) write(e *logEntry[Msg]) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return writeFailed(os.ErrClosed)
+	}
+	if s.file == nil || s.remaining == 0 {
+		if err := s.rotate(); err != nil {
+			return writeFailed(err)
+		}
+	}
+
+	rec, err := s.encode(e)
+	if err != nil {
+		return writeFailed(err)
+	}
+
+	if _, err := s.file.Write(rec); err != nil {
+		// don't leave a torn record behind for subsequent writes to follow
+		_ = s.file.Truncate(s.size)
+		_, _ = s.file.Seek(s.size, io.SeekStart)
+		return writeFailed(err)
+	}
+	s.size += int64(len(rec))
+	s.next = s.next.Next()
+	s.remaining--
+
+	if s.sync == storage.SyncAlways {
+		if err := s.file.Sync(); err != nil {
+			return writeFailed(err)
+		}
+	}
+	return nil
+}
+
+// encode returns the record for an entry. A nil entry is encoded as a removed
+// record
+func (s *store[Msg]) encode(e *logEntry[Msg]) ([]byte, error) {
+	if e == nil {
+		rec := make([]byte, recordHeaderSize)
+		binary.BigEndian.PutUint32(rec[0:], removedRecordSize)
+		binary.BigEndian.PutUint32(rec[4:], crc32.ChecksumIEEE(rec[8:]))
+		return rec, nil
+	}
+	data, err := s.codec.Marshal(e.msg)
+	if err != nil {
+		return nil, err
+	}
+	size := uint32(len(data))
+	if e.producer != id.Nil || e.meta != nil {
+		data = append(encodeMetadata(e), data...)
+		size = uint32(len(data)) | recordMetaFlag
+	}
+	rec := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(rec[0:], size)
+	binary.BigEndian.PutUint64(rec[8:], uint64(e.createdAt.UnixNano()))
+	copy(rec[recordHeaderSize:], data)
+	binary.BigEndian.PutUint32(rec[4:], crc32.ChecksumIEEE(rec[8:]))
+	return rec, nil
+}
+
+// encodeMetadata returns the payload prefix of a record, consisting of the
+// length of the prefix, the entry's producer, and its metadata
+func encodeMetadata[Msg any](e *logEntry[Msg]) []byte {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 4))
+	buf.Write(e.producer[:])
+
+	m := e.meta
+	if m == nil {
+		m = &metadata{}
+	}
+	var nanos int64
+	if !m.timestamp.IsZero() {
+		nanos = m.timestamp.UnixNano()
+	}
+	_ = binary.Write(&buf, binary.BigEndian, nanos)
+	if m.key != nil {
+		writeBytes(&buf, m.key.Bytes())
+	} else {
+		_ = binary.Write(&buf, binary.BigEndian, uint32(math.MaxUint32))
+	}
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(m.headers)))
+	for k, v := range m.headers {
+		writeBytes(&buf, []byte(k))
+		writeBytes(&buf, []byte(v))
+	}
+	if !m.expiresAt.IsZero() || !m.notBefore.IsZero() {
+		// optional, so that earlier records remain readable
+		_ = binary.Write(&buf, binary.BigEndian, unixNanos(m.expiresAt))
+	}
+	if !m.notBefore.IsZero() {
+		_ = binary.Write(&buf, binary.BigEndian, unixNanos(m.notBefore))
+	}
+
+	res := buf.Bytes()
+	binary.BigEndian.PutUint32(res, uint32(len(res)))
+	return res
+}
+
+// decodeMetadata applies a record's payload prefix to an entry, returning the
+// remainder of the payload
+func decodeMetadata[Msg any](e *logEntry[Msg], data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, errHeader
+	}
+	size := binary.BigEndian.Uint32(data)
+	if size < 4 || uint64(size) > uint64(len(data)) {
+		return nil, errHeader
+	}
+	r := bytes.NewReader(data[4:size])
+
+	var nanos int64
+	var keyLen, count uint32
+	if _, err := io.ReadFull(r, e.producer[:]); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &nanos); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &keyLen); err != nil {
+		return nil, err
+	}
+
+	m := &metadata{}
+	if nanos != 0 {
+		m.timestamp = time.Unix(0, nanos)
+	}
+	if keyLen != math.MaxUint32 {
+		key, err := readBytes(r, keyLen)
+		if err != nil {
+			return nil, err
+		}
+		m.key = topic.StringKey(key)
+	}
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < count; i++ {
+		if m.headers == nil {
+			m.headers = topic.Headers{}
+		}
+		k, err := readPrefixed(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := readPrefixed(r)
+		if err != nil {
+			return nil, err
+		}
+		m.headers[string(k)] = string(v)
+	}
+	for _, t := range []*time.Time{&m.expiresAt, &m.notBefore} {
+		if r.Len() == 0 {
+			break
+		}
+		if err := binary.Read(r, binary.BigEndian, &nanos); err != nil {
+			return nil, err
+		}
+		if nanos != 0 {
+			*t = time.Unix(0, nanos)
+		}
+	}
+	if m.key != nil || m.headers != nil || !m.timestamp.IsZero() ||
+		!m.expiresAt.IsZero() || !m.notBefore.IsZero() {
+		e.meta = m
+	}
+	return data[size:], nil
+}
+
+// unixNanos returns the Time in nanoseconds since the Unix epoch, or zero for
+// the zero Time
+func unixNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func writeBytes(buf *bytes.Buffer, b []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(b)))
+	buf.Write(b)
+}
+
+func readPrefixed(r *bytes.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	return readBytes(r, n)
+}
+
+func readBytes(r *bytes.Reader, n uint32) ([]byte, error) {
+	if int64(n) > int64(r.Len()) {
+		return nil, errHeader
+	}
+	res := make([]byte, n)
+	_, err := io.ReadFull(r, res)
+	return res, err
+}
+
+// rewrite replaces the segment file starting at the specified Offset with
+// the current contents of a full segment. The replacement is written to a
+// temporary file first, so that an interrupted rewrite leaves the original
+// in place
+func (s *store[Msg]) rewrite(base retention.Offset, seg *segment[Msg]) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return writeFailed(os.ErrClosed)
+	}
+
+	var buf bytes.Buffer
+	head := make([]byte, segmentHeaderSize)
+	copy(head, segmentMagic)
+	binary.BigEndian.PutUint32(head[len(segmentMagic):], seg.cap)
+	buf.Write(head)
+	for i, n := uint32(0), seg.length(); i < n; i++ {
+		rec, err := s.encode(seg.entry(i))
+		if err != nil {
+			return writeFailed(err)
+		}
+		buf.Write(rec)
+	}
+
+	path := s.filePath(base)
+	tmp := path + segmentTempExt
+	if err := s.writeFile(tmp, buf.Bytes()); err != nil {
+		_ = os.Remove(tmp)
+		return writeFailed(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return writeFailed(err)
+	}
+	return nil
+}
+
+func (s *store[_]) writeFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if s.sync != storage.SyncNever {
+		if err := file.Sync(); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+func (s *store[_]) rotate() error {
+	if err := s.closeFile(); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(
+		s.filePath(s.next), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644,
+	)
+	if err != nil {
+		return err
+	}
+	if err := resetSegmentFile(file, s.capacity); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if _, err := file.Seek(segmentHeaderSize, io.SeekStart); err != nil {
+		_ = file.Close()
+		return err
+	}
+	s.file = file
+	s.size = segmentHeaderSize
+	s.base = s.next
+	s.remaining = s.capacity
+	return nil
+}
+
+// remove deletes the segment file starting at the specified Offset. If that
+// is the current segment file, an empty successor is started so that the
+// Log's Offsets survive a restart
+func (s *store[_]) remove(base retention.Offset) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file != nil && s.base == base {
+		if err := s.closeFile(); err != nil {
+			return err
+		}
+		if err := os.Remove(s.filePath(base)); err != nil {
+			return err
+		}
+		if s.closed {
+			return nil
+		}
+		return s.rotate()
+	}
+	return os.Remove(s.filePath(base))
+}
+
+func (s *store[_]) close() error {
+	s.Lock()
+	defer s.Unlock()
+	s.closed = true
+	return s.closeFile()
+}
+
+func (s *store[_]) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+	file := s.file
+	s.file = nil
+	if s.sync != storage.SyncNever {
+		if err := file.Sync(); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+func (s *store[_]) filePath(base retention.Offset) string {
+	name := fmt.Sprintf("%020d%s", uint64(base), segmentFileExt)
+	return filepath.Join(s.path, name)
+}
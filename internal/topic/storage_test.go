@@ -0,0 +1,186 @@
+package topic_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/retention"
+	"github.com/caravan/essentials/topic/storage"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+func TestPersistentTopic(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[string](config.Persistent(dir))
+	l := top.(*internal.Topic[string])
+	for i := 0; i < segmentSize+5; i++ {
+		as.Nil(l.Put(string(rune('a' + i%26))))
+	}
+	top.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	as.Equal(2, len(files))
+
+	top = internal.Make[string](config.Persistent(dir))
+	as.Equal(topic.Length(segmentSize+5), top.Length())
+	c := top.NewConsumer(topic.StartAt(topic.AtOffset(topic.Offset(segmentSize))))
+	as.Equal(string(rune('a'+segmentSize%26)), message.MustReceive[string](c))
+
+	p := top.NewProducer()
+	p.Send() <- "appended"
+	top.Close()
+
+	top = internal.Make[string](config.Persistent(dir))
+	e, o, ok := top.(*internal.Topic[string]).Get(
+		retention.Offset(segmentSize + 5),
+	)
+	as.True(ok)
+	as.Equal("appended", e)
+	as.Equal(retention.Offset(segmentSize+5), o)
+	top.Close()
+}
+
+func TestPersistentTornWrite(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+
+	top := internal.Make[int](
+		config.Persistent(dir), config.StorageSync(storage.SyncAlways),
+	)
+	l := top.(*internal.Topic[int])
+	for i := 0; i < 5; i++ {
+		as.Nil(l.Put(i))
+	}
+	top.Close()
+
+	// simulate a crash in the middle of writing a record
+	files, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	as.Equal(1, len(files))
+	f, err := os.OpenFile(files[0], os.O_WRONLY|os.O_APPEND, 0)
+	as.Nil(err)
+	_, err = f.Write([]byte{0, 0, 0, 9, 1, 2})
+	as.Nil(err)
+	as.Nil(f.Close())
+
+	top = internal.Make[int](config.Persistent(dir))
+	l = top.(*internal.Topic[int])
+	as.Equal(topic.Length(5), top.Length())
+	as.Nil(l.Put(5))
+	top.Close()
+
+	top = internal.Make[int](config.Persistent(dir))
+	c := top.NewConsumer()
+	for i := 0; i < 6; i++ {
+		as.Equal(i, message.MustReceive[int](c))
+	}
+	top.Close()
+}
+
+func TestPersistentDiscarding(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[int](config.Persistent(dir), config.Consumed)
+	l := top.(*internal.Topic[int])
+	for i := 0; i < segmentSize*2; i++ {
+		as.Nil(l.Put(i))
+	}
+	time.Sleep(10 * time.Millisecond)
+	_, o, _ := l.Get(0)
+	as.Equal(retention.Offset(segmentSize*2), o)
+	top.Close()
+
+	// the Offsets survive even though every message was discarded
+	top = internal.Make[int](config.Persistent(dir))
+	as.Equal(topic.Length(segmentSize*2), top.Length())
+	as.Equal(retention.Offset(segmentSize*2), top.(topic.Locator).Start())
+	top.Close()
+}
+
+func TestPersistentCorruptSegment(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	segmentSize := config.DefaultSegmentIncrement
+
+	top := internal.Make[int](config.Persistent(dir))
+	l := top.(*internal.Topic[int])
+	for i := 0; i < segmentSize*2; i++ {
+		as.Nil(l.Put(i))
+	}
+	top.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	as.Nil(os.Truncate(files[0], 20))
+
+	top, err := essentials.OpenTopic[int](config.Persistent(dir))
+	as.Nil(top)
+	as.Error(err)
+}
+
+func TestPersistentClosedPut(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int](config.Persistent(t.TempDir()))
+	top.Close()
+	as.Error(top.(*internal.Topic[int]).Put(1))
+}
+
+type failingCodec struct{}
+
+func (failingCodec) Marshal(v any) ([]byte, error) {
+	if v == "bad" {
+		return nil, errors.New("can't marshal")
+	}
+	return storage.JSON.Marshal(v)
+}
+
+func (failingCodec) Unmarshal(data []byte, v any) error {
+	return storage.JSON.Unmarshal(data, v)
+}
+
+func TestPersistentProducerFailure(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[string](
+		config.Persistent(t.TempDir()), config.StorageCodec(failingCodec{}),
+	)
+	p := top.NewProducer()
+	_, err := p.Produce("bad")
+	as.ErrorContains(err, "can't marshal")
+	as.False(closer.IsClosed(p))
+
+	p.Send() <- "bad"
+	select {
+	case <-p.IsClosed():
+	case <-time.After(time.Second):
+		as.Fail("producer wasn't closed")
+	}
+	_, err = p.Produce("good")
+	as.ErrorContains(err, "can't marshal")
+	as.Equal(topic.Length(0), top.Length())
+
+	kt := internal.MakePartitioned[string](2,
+		config.Persistent(t.TempDir()), config.StorageCodec(failingCodec{}),
+	)
+	kp := kt.NewProducer()
+	kp.Send() <- topic.Keyed[string]{Message: "bad"}
+	select {
+	case <-kp.IsClosed():
+	case <-time.After(time.Second):
+		as.Fail("keyed producer wasn't closed")
+	}
+	kt.Close()
+	top.Close()
+}
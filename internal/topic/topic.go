@@ -1,9 +1,12 @@
 package topic
 
 import (
+	"errors"
+	"iter"
 	"sync"
 	"time"
 
+	"github.com/caravan/essentials/closer"
 	"github.com/caravan/essentials/id"
 	"github.com/caravan/essentials/internal/sync/channel"
 	"github.com/caravan/essentials/topic"
@@ -16,11 +19,17 @@ type (
 	// Topic is the internal implementation of a Topic
 	Topic[Msg any] struct {
 		*config.Config
+		closer.Closer
 		retentionState retention.State
 		log            *Log[Msg]
+		producers      *producers
 		cursors        *cursors[Msg]
+		groups         *groups[Msg]
+		ackGroups      *ackGroups[Msg]
+		deadLetter     topic.Topic[topic.DeadLetter[Msg]]
 		observers      *topicObservers
 		vacuumReady    *channel.ReadyWait
+		schedule       *schedule
 	}
 
 	// topicObservers manages a set of callbacks for observers of a Topic
@@ -32,22 +41,80 @@ type (
 
 // Make instantiates a new internal Topic instance
 func Make[Msg any](o ...config.Option) topic.Topic[Msg] {
+	return makeTopic[Msg](makeConfig(o...))
+}
+
+// Open instantiates a new internal Topic instance, returning an error rather
+// than panicking if the Options are invalid or its storage can't be opened
+func Open[Msg any](o ...config.Option) (*Topic[Msg], error) {
+	cfg, err := applyConfig(o...)
+	if err != nil {
+		return nil, err
+	}
+	return openTopic[Msg](cfg)
+}
+
+func makeConfig(o ...config.Option) *config.Config {
+	cfg, err := applyConfig(o...)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func applyConfig(o ...config.Option) (*config.Config, error) {
 	cfg := &config.Config{}
 	withDefaults := append(o, config.Defaults)
 	if err := config.ApplyOptions(cfg, withDefaults...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func makeTopic[Msg any](cfg *config.Config) *Topic[Msg] {
+	res, err := openTopic[Msg](cfg)
+	if err != nil {
 		panic(err)
 	}
+	return res
+}
+
+func openTopic[Msg any](cfg *config.Config) (*Topic[Msg], error) {
+	var deadLetter topic.Topic[topic.DeadLetter[Msg]]
+	if cfg.DeadLetterTopic != nil {
+		dl, ok := cfg.DeadLetterTopic.(topic.Topic[topic.DeadLetter[Msg]])
+		if !ok {
+			return nil, errors.New(config.ErrDeadLetterTypeMismatch)
+		}
+		deadLetter = dl
+	}
+
+	log, err := makeLog[Msg](cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	res := &Topic[Msg]{
 		Config:         cfg,
 		retentionState: cfg.RetentionPolicy.InitialState(),
+		producers:      makeProducers(),
 		cursors:        makeCursors[Msg](),
+		groups:         makeGroups[Msg](),
+		ackGroups:      makeAckGroups[Msg](),
+		deadLetter:     deadLetter,
 		observers:      makeLogObservers(),
-		log:            makeLog[Msg](cfg),
+		schedule:       makeSchedule(cfg.Clock),
+		log:            log,
 	}
+	res.Closer = makeCloser(func() {
+		if err := log.close(); err != nil {
+			reportError(err)
+		}
+		res.notifyObservers()
+	})
 
 	res.startVacuuming()
-	return res
+	return res, nil
 }
 
 // Length returns the virtual size of the Topic
@@ -55,33 +122,168 @@ func (t *Topic[_]) Length() topic.Length {
 	return t.log.length()
 }
 
+// Close closes the Topic. Outstanding Producers are closed and new Producers
+// will be returned already closed. Consumers will continue to receive any
+// messages that are still retained, after which their channels are closed
+func (t *Topic[_]) Close() {
+	t.log.unblock()
+	t.producers.close()
+	t.Closer.Close()
+}
+
 // NewProducer instantiates a new Topic Producer
 func (t *Topic[Msg]) NewProducer() topic.Producer[Msg] {
 	return makeProducer(t)
 }
 
-// NewConsumer instantiates a new Topic Consumer
-func (t *Topic[Msg]) NewConsumer() topic.Consumer[Msg] {
-	return makeConsumer(t.makeCursor(), t.BackoffGenerator)
+// NewEnvelopeProducer instantiates a new Topic EnvelopeProducer
+func (t *Topic[Msg]) NewEnvelopeProducer() topic.EnvelopeProducer[Msg] {
+	return makeEnvelopeProducer(t)
+}
+
+// NewConsumer instantiates a new Topic Consumer. If a consumer group is
+// specified, the Consumer joins that group as one of its members
+func (t *Topic[Msg]) NewConsumer(o ...topic.ConsumerOption) topic.Consumer[Msg] {
+	cfg := topic.ApplyConsumerOptions(o...)
+	if cfg.Group != "" {
+		return t.groups.join(t, cfg.Group, cfg.Position)
+	}
+	return makeConsumer(t.makeCursor(cfg.Position), t.BackoffGenerator)
+}
+
+// NewAckConsumer instantiates a new Topic AckConsumer. If a consumer group is
+// specified, the AckConsumer joins that group as one of its members, and the
+// group's unacknowledged messages may be redelivered to any of them
+func (t *Topic[Msg]) NewAckConsumer(
+	o ...topic.ConsumerOption,
+) topic.AckConsumer[Msg] {
+	cfg := topic.ApplyConsumerOptions(o...)
+	if cfg.Group != "" {
+		return t.ackGroups.join(t, cfg)
+	}
+	c := makeCursor(t, cfg.Position)
+	res := makeAckConsumer(c, t.BackoffGenerator, cfg, nil)
+	t.trackCursor(c)
+	return res
+}
+
+// NewEnvelopeConsumer instantiates a new Topic EnvelopeConsumer, or returns an
+// error if it's asked to join a consumer group
+func (t *Topic[Msg]) NewEnvelopeConsumer(
+	o ...topic.ConsumerOption,
+) (topic.EnvelopeConsumer[Msg], error) {
+	cfg := topic.ApplyConsumerOptions(o...)
+	if cfg.Group != "" {
+		return nil, errors.New(topic.ErrEnvelopeGroupUnsupported)
+	}
+	c := t.makeCursor(cfg.Position)
+	return makeEnvelopeConsumer(c, t.BackoffGenerator), nil
+}
+
+// Consumers returns the number of Consumers currently reading from the Topic,
+// counting each member of a consumer group
+func (t *Topic[_]) Consumers() int {
+	groups, members := t.groups.counts()
+	ackGroups, ackMembers := t.ackGroups.counts()
+	return t.cursors.count() - groups - ackGroups + members + ackMembers
+}
+
+// Start returns the earliest Offset still retained by the Topic
+func (t *Topic[_]) Start() retention.Offset {
+	return t.log.start()
+}
+
+// End returns the Offset that the next message put to the Topic will be
+// assigned
+func (t *Topic[_]) End() retention.Offset {
+	return retention.Offset(t.log.length())
+}
+
+// Find returns the Offset of the first retained entry that was produced at or
+// after the specified Time, or the End Offset if there is no such entry
+func (t *Topic[_]) Find(tm time.Time) retention.Offset {
+	return t.log.find(tm)
+}
+
+// Snapshot returns an iterator over the messages that are retained by the
+// Topic when iteration begins. Iteration is backed by a cursor, so retention
+// policies won't discard the remaining entries until it completes
+func (t *Topic[Msg]) Snapshot() iter.Seq2[retention.Offset, Msg] {
+	return func(yield func(retention.Offset, Msg) bool) {
+		end := t.End()
+		c := t.makeCursor(topic.Earliest)
+		defer c.Close()
+		for {
+			e, ok := c.head()
+			if !ok {
+				return
+			}
+			o := c.position()
+			if o >= end || !yield(o, e) {
+				return
+			}
+			c.advance()
+		}
+	}
 }
 
 // Get consumes a message starting at the specified virtual Offset within the
 // Topic. If the Offset is no longer being retained, the next available Offset
-// will be consumed. The actual Offset read is returned
+// will be consumed. The actual Offset read is returned. Delayed messages are
+// returned whether or not they're due
 func (t *Topic[Msg]) Get(o retention.Offset) (Msg, retention.Offset, bool) {
-	defer t.vacuumReady.Notify()
-	e, o, ok := t.log.get(o)
+	e, o, ok := t.getEntry(o)
 	return e.msg, o, ok
 }
 
-// Put adds the specified Message to the Topic
-func (t *Topic[Msg]) Put(msg Msg) {
-	t.log.put(msg)
+func (t *Topic[Msg]) getEntry(
+	o retention.Offset,
+) (*logEntry[Msg], retention.Offset, bool) {
+	defer t.vacuumReady.Notify()
+	return t.log.get(o)
+}
+
+// Put adds the specified Message to the Topic. An error is returned if the
+// Topic is persistent and the Message could not be written to storage
+func (t *Topic[Msg]) Put(msg Msg) error {
+	_, err := t.put(&logEntry[Msg]{msg: msg}, nil)
+	return ignoreDropped(err)
+}
+
+// put adds an entry to the Topic. If the Topic is bounded and full, closing
+// the cancel channel abandons the put
+func (t *Topic[Msg]) put(
+	e *logEntry[Msg], cancel <-chan struct{},
+) (retention.Offset, error) {
+	o, err := t.log.put(e, cancel)
+	if err != nil {
+		return 0, err
+	}
+	if nb := e.notBefore(); nb.After(t.Clock.Now()) {
+		t.schedule.add(nb, t.notifyObservers, t.IsClosed())
+	}
 	t.notifyObservers()
+	return o, nil
 }
 
-func (t *Topic[_]) isClosed() bool {
-	return false
+// putBatch adds entries to the Topic, notifying its observers only once
+func (t *Topic[Msg]) putBatch(
+	entries []*logEntry[Msg], cancel <-chan struct{},
+) ([]retention.Offset, error) {
+	res, err := t.log.putBatch(entries, cancel)
+	if len(res) != 0 {
+		t.notifyObservers()
+	}
+	return res, err
+}
+
+// ignoreDropped discards the error returned when a message is silently
+// dropped by a bounded Topic, which is only reported to synchronous producers
+func ignoreDropped(err error) error {
+	if err == topic.ErrDropped {
+		return nil
+	}
+	return err
 }
 
 func (t *Topic[_]) startVacuuming() {
@@ -91,11 +293,16 @@ func (t *Topic[_]) startVacuuming() {
 	t.observers.add(vacuumID, ready.Notify)
 
 	go func() {
+		defer t.observers.remove(vacuumID)
+		timer := channel.MakeTimer(t.Clock.NewTimer)
+		defer timer.Stop()
 		b := backoff.DefaultGenerator
 		next := b()
-		for !t.isClosed() {
+		for {
 			select {
-			case <-channel.Timeout(next()):
+			case <-t.IsClosed():
+				return
+			case <-timer.Reset(next()):
 			case <-ready.Wait():
 			}
 			if t.log.canVacuum() {
@@ -107,16 +314,24 @@ func (t *Topic[_]) startVacuuming() {
 }
 
 func (t *Topic[Msg]) vacuum() {
+	t.log.expire()
 	baseStats := t.baseRetentionStatistics()
+	if p, ok := retention.Compaction(t.RetentionPolicy); ok {
+		t.compact(p, baseStats)
+	}
 	t.log.vacuum(func(e *segment[Msg]) bool {
 		start := t.log.start()
-		firstTimestamp, lastTimestamp := e.timeRange()
+		firstTimestamp, lastTimestamp, _ := e.timeRange()
 		stats := *baseStats()
+		logStats := *stats.Log
+		logStats.Bytes = retention.Size(t.log.byteSize())
+		stats.Log = &logStats
 		stats.Entries = &retention.EntriesStatistics{
 			FirstOffset:    start,
 			LastOffset:     start + retention.Offset(e.length()-1),
 			FirstTimestamp: firstTimestamp,
 			LastTimestamp:  lastTimestamp,
+			Bytes:          retention.Size(e.byteSize()),
 		}
 		s, r := t.RetentionPolicy.Retain(t.retentionState, &stats)
 		t.retentionState = s
@@ -124,14 +339,32 @@ func (t *Topic[Msg]) vacuum() {
 	})
 }
 
+func (t *Topic[Msg]) compact(
+	p retention.CompactedPolicy, baseStats func() *retention.Statistics,
+) {
+	t.log.compact(func(o retention.Offset, e *logEntry[Msg]) bool {
+		stats := *baseStats()
+		stats.Entries = &retention.EntriesStatistics{
+			FirstOffset:    o,
+			LastOffset:     o,
+			FirstTimestamp: e.createdAt,
+			LastTimestamp:  e.createdAt,
+			Bytes:          retention.Size(e.size),
+		}
+		return p.RetainTombstone(&stats)
+	})
+}
+
 func (t *Topic[_]) baseRetentionStatistics() func() *retention.Statistics {
 	var base *retention.Statistics
 	return func() *retention.Statistics {
 		if base == nil {
 			base = &retention.Statistics{
-				CurrentTime: time.Now(),
+				CurrentTime: t.Clock.Now(),
 				Log: &retention.LogStatistics{
 					Length:        t.log.length(),
+					Bytes:         retention.Size(t.log.byteSize()),
+					Expired:       retention.Count(t.log.expiredCount()),
 					CursorOffsets: t.cursors.offsets(),
 				},
 			}
@@ -140,11 +373,15 @@ func (t *Topic[_]) baseRetentionStatistics() func() *retention.Statistics {
 	}
 }
 
-func (t *Topic[Msg]) makeCursor() *cursor[Msg] {
-	c := makeCursor(t)
+func (t *Topic[Msg]) makeCursor(p topic.Position) *cursor[Msg] {
+	c := makeCursor(t, p)
+	t.trackCursor(c)
+	return c
+}
+
+func (t *Topic[Msg]) trackCursor(c *cursor[Msg]) {
 	t.cursors.track(c)
 	t.observers.add(c.id, c.ready.Notify)
-	return c
 }
 
 func (t *Topic[_]) notifyObservers() {
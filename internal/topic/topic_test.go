@@ -4,7 +4,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
 	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/clock/clocktest"
 	"github.com/caravan/essentials/topic/config"
 	"github.com/caravan/essentials/topic/retention"
 	"github.com/stretchr/testify/assert"
@@ -82,3 +85,136 @@ func TestLogDiscardEverything(t *testing.T) {
 	as.Equal(retention.Offset(segmentSize), o)
 	as.False(ok)
 }
+
+func TestTopicClose(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[any](config.Permanent)
+	p := top.NewProducer()
+	c := top.NewConsumer()
+	p.Send() <- "first value"
+	p.Send() <- "second value"
+
+	top.Close()
+	as.True(closer.IsClosed(top))
+	as.True(closer.IsClosed(p))
+	top.Close()
+	as.True(closer.IsClosed(top)) // still closed
+
+	p2 := top.NewProducer()
+	as.True(closer.IsClosed(p2))
+	as.False(message.Send[any](p2, "rejected"))
+
+	as.Equal("first value", message.MustReceive[any](c))
+	as.Equal("second value", message.MustReceive[any](c))
+	_, ok := message.Receive[any](c)
+	as.False(ok)
+	as.True(closer.IsClosed(c))
+}
+
+func TestClosedTopicConsumer(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[any](config.Permanent)
+	p := top.NewProducer()
+	p.Send() <- "retained value"
+	top.Close()
+
+	c := top.NewConsumer()
+	as.Equal("retained value", message.MustReceive[any](c))
+	_, ok := message.Receive[any](c)
+	as.False(ok)
+}
+
+func TestTopicCloseWaitingConsumer(t *testing.T) {
+	as := assert.New(t)
+
+	top := internal.Make[any]()
+	c := top.NewConsumer()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		top.Close()
+	}()
+	_, ok := message.Receive[any](c)
+	as.False(ok)
+}
+
+func TestSnapshot(t *testing.T) {
+	as := assert.New(t)
+	top := internal.Make[int]()
+	l := top.(*internal.Topic[int])
+	for i := 0; i < 5; i++ {
+		as.Nil(l.Put(i * 10))
+	}
+
+	var offsets []topic.Offset
+	var res []int
+	for o, m := range top.Snapshot() {
+		if o == 0 {
+			// messages put during iteration aren't part of the snapshot
+			as.Nil(l.Put(50))
+		}
+		offsets = append(offsets, o)
+		res = append(res, m)
+	}
+	as.Equal([]topic.Offset{0, 1, 2, 3, 4}, offsets)
+	as.Equal([]int{0, 10, 20, 30, 40}, res)
+	top.Close()
+}
+
+func TestSnapshotBreak(t *testing.T) {
+	as := assert.New(t)
+	segmentSize := config.DefaultSegmentIncrement
+	top := internal.Make[int](config.Consumed)
+	l := top.(*internal.Topic[int])
+	c := top.NewConsumer()
+	for i := 0; i < segmentSize*2; i++ {
+		as.Nil(l.Put(i))
+	}
+	c.Close()
+
+	// the snapshot's cursor holds back the Consumed policy
+	for o := range top.Snapshot() {
+		time.Sleep(20 * time.Millisecond)
+		as.Equal(topic.Offset(0), l.Start())
+		if o == 0 {
+			break
+		}
+	}
+
+	// until breaking out of the iteration closes it
+	as.Nil(l.Put(segmentSize * 2))
+	as.Eventually(func() bool {
+		return l.Start() != 0
+	}, time.Second, 5*time.Millisecond)
+	top.Close()
+}
+
+func TestTopicClock(t *testing.T) {
+	as := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	clk := clocktest.Make(start)
+	top := internal.Make[int](
+		config.Clock(clk), config.Timed(time.Minute),
+	)
+	l := top.(*internal.Topic[int])
+
+	for i := 0; i < config.DefaultSegmentIncrement; i++ {
+		as.Nil(l.Put(i))
+	}
+	as.Equal(retention.Offset(0), l.Find(start))
+
+	clk.Advance(time.Hour)
+	as.Nil(l.Put(config.DefaultSegmentIncrement))
+	as.Equal(
+		retention.Offset(config.DefaultSegmentIncrement),
+		l.Find(start.Add(time.Hour)),
+	)
+
+	// the first segment is now expired, and is discarded once vacuumed
+	as.Eventually(func() bool {
+		return l.Start() == config.DefaultSegmentIncrement
+	}, time.Second, time.Millisecond)
+	top.Close()
+}
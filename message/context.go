@@ -0,0 +1,122 @@
+package message
+
+import (
+	"context"
+)
+
+// Error is returned by the context-aware helpers, identifying why a message
+// couldn't be sent or received
+type Error string
+
+// Errors returned by the context-aware helpers. ErrCancelled and ErrTimedOut
+// also match context.Canceled and context.DeadlineExceeded respectively
+const (
+	ErrClosed    Error = "sender or receiver is closed"
+	ErrCancelled Error = "operation was cancelled"
+	ErrTimedOut  Error = "operation timed out"
+)
+
+// SendContext sends a message to a ClosingSender, blocking until it is
+// accepted, the ClosingSender is closed, or the Context is done
+func SendContext[Msg any](
+	ctx context.Context, s ClosingSender[Msg], m Msg,
+) (err error) {
+	defer func() {
+		// the Sender's channel was closed while sending
+		if recover() != nil {
+			err = ErrClosed
+		}
+	}()
+
+	select {
+	case <-s.IsClosed():
+		return ErrClosed
+	case <-ctx.Done():
+		return contextError(ctx)
+	default:
+	}
+
+	select {
+	case <-s.IsClosed():
+		return ErrClosed
+	case <-ctx.Done():
+		return contextError(ctx)
+	case s.Send() <- m:
+		return nil
+	}
+}
+
+// SendBatchContext sends messages to a ClosingSender in order, stopping at the
+// first one that can't be sent. The number of messages sent is returned
+func SendBatchContext[Msg any](
+	ctx context.Context, s ClosingSender[Msg], msgs []Msg,
+) (int, error) {
+	for i, m := range msgs {
+		if err := SendContext(ctx, s, m); err != nil {
+			return i, err
+		}
+	}
+	return len(msgs), nil
+}
+
+// ReceiveContext returns the next message, blocking until one is available,
+// the Receiver is closed, or the Context is done
+func ReceiveContext[Msg any](ctx context.Context, r Receiver[Msg]) (Msg, error) {
+	var zero Msg
+	select {
+	case <-ctx.Done():
+		return zero, contextError(ctx)
+	default:
+	}
+
+	select {
+	case <-ctx.Done():
+		return zero, contextError(ctx)
+	case m, ok := <-r.Receive():
+		if !ok {
+			return zero, ErrClosed
+		}
+		return m, nil
+	}
+}
+
+// ReceiveBatchContext receives up to the specified number of messages. If it
+// stops early because the Receiver is closed or the Context is done, the
+// messages received up to that point are returned along with the error
+func ReceiveBatchContext[Msg any](
+	ctx context.Context, r Receiver[Msg], n int,
+) ([]Msg, error) {
+	res := make([]Msg, 0, n)
+	for len(res) < n {
+		m, err := ReceiveContext(ctx, r)
+		if err != nil {
+			return res, err
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Is allows ErrCancelled and ErrTimedOut to be matched against their
+// corresponding context errors using errors.Is
+func (e Error) Is(target error) bool {
+	switch e {
+	case ErrCancelled:
+		return target == context.Canceled
+	case ErrTimedOut:
+		return target == context.DeadlineExceeded
+	default:
+		return false
+	}
+}
+
+func contextError(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrTimedOut
+	}
+	return ErrCancelled
+}
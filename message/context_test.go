@@ -0,0 +1,92 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendContext(t *testing.T) {
+	as := assert.New(t)
+	top := essentials.NewTopic[string]()
+	p := top.NewProducer()
+	c := top.NewConsumer()
+
+	as.Nil(message.SendContext[string](context.Background(), p, "hello"))
+	msg, err := message.ReceiveContext[string](context.Background(), c)
+	as.Nil(err)
+	as.Equal("hello", msg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = message.SendContext[string](ctx, p, "cancelled")
+	as.Equal(message.ErrCancelled, err)
+	as.True(errors.Is(err, context.Canceled))
+
+	p.Close()
+	err = message.SendContext[string](context.Background(), p, "closed")
+	as.Equal(message.ErrClosed, err)
+	c.Close()
+	top.Close()
+}
+
+func TestReceiveContext(t *testing.T) {
+	as := assert.New(t)
+	top := essentials.NewTopic[string]()
+	c := top.NewConsumer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	msg, err := message.ReceiveContext[string](ctx, c)
+	as.Equal("", msg)
+	as.Equal(message.ErrTimedOut, err)
+	as.True(errors.Is(err, context.DeadlineExceeded))
+	as.False(errors.Is(err, context.Canceled))
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	ccancel()
+	_, err = message.ReceiveContext[string](cctx, c)
+	as.Equal(message.ErrCancelled, err)
+
+	top.Close()
+	_, err = message.ReceiveContext[string](context.Background(), c)
+	as.Equal(message.ErrClosed, err)
+	as.EqualError(err, string(message.ErrClosed))
+}
+
+func TestBatchContext(t *testing.T) {
+	as := assert.New(t)
+	top := essentials.NewTopic[int]()
+	p := top.NewProducer()
+	c := top.NewConsumer()
+
+	n, err := message.SendBatchContext[int](
+		context.Background(), p, []int{1, 2, 3},
+	)
+	as.Equal(3, n)
+	as.Nil(err)
+
+	res, err := message.ReceiveBatchContext[int](context.Background(), c, 2)
+	as.Equal([]int{1, 2}, res)
+	as.Nil(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err = message.ReceiveBatchContext[int](ctx, c, 2)
+	as.Equal([]int{3}, res)
+	as.Equal(message.ErrTimedOut, err)
+
+	p.Close()
+	n, err = message.SendBatchContext[int](
+		context.Background(), p, []int{4, 5},
+	)
+	as.Equal(0, n)
+	as.Equal(message.ErrClosed, err)
+	c.Close()
+	top.Close()
+}
@@ -2,10 +2,10 @@ package message
 
 import (
 	"errors"
+	"iter"
 	"time"
 
 	"github.com/caravan/essentials/closer"
-	"github.com/caravan/essentials/internal/sync/channel"
 )
 
 type (
@@ -30,8 +30,10 @@ const (
 // Poll will wait up until the specified Duration for a message to possibly be
 // returned, advancing the Receiver's Cursor upon success
 func Poll[Msg any](r Receiver[Msg], d time.Duration) (Msg, bool) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 	select {
-	case <-channel.Timeout(d):
+	case <-timer.C:
 		var zero Msg
 		return zero, false
 	case m, ok := <-r.Receive():
@@ -53,3 +55,16 @@ func MustReceive[Msg any](r Receiver[Msg]) Msg {
 	}
 	panic(errors.New(ErrReceiverClosed))
 }
+
+// All returns an iterator over the messages of a Receiver. Iteration ends when
+// the Receiver is closed. Breaking out of the iteration leaves the Receiver
+// open, and any message not yet received remains available to it
+func All[Msg any](r Receiver[Msg]) iter.Seq[Msg] {
+	return func(yield func(Msg) bool) {
+		for m := range r.Receive() {
+			if !yield(m) {
+				return
+			}
+		}
+	}
+}
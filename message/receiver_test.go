@@ -41,3 +41,29 @@ func TestMustReceive(t *testing.T) {
 	}()
 	message.MustReceive[any](c)
 }
+
+func TestAll(t *testing.T) {
+	as := assert.New(t)
+	top := essentials.NewTopic[int]()
+	p := top.NewProducer()
+	c := top.NewConsumer()
+	for i := 0; i < 5; i++ {
+		message.Send[int](p, i)
+	}
+
+	var res []int
+	for m := range message.All[int](c) {
+		if m == 3 {
+			break
+		}
+		res = append(res, m)
+	}
+	as.Equal([]int{0, 1, 2}, res)
+
+	top.Close()
+	res = nil
+	for m := range message.All[int](c) {
+		res = append(res, m)
+	}
+	as.Equal([]int{4}, res)
+}
@@ -0,0 +1,41 @@
+package request
+
+import (
+	"time"
+
+	"github.com/caravan/essentials/id"
+)
+
+type (
+	// Request is the message that a Requester publishes to its request
+	// Topic. ReplyTo names the Topic, in the same Broker, that a Responder
+	// routes its Reply to
+	Request[Req any] struct {
+		ID       id.ID
+		ReplyTo  string
+		Deadline time.Time
+		Message  Req
+	}
+
+	// Reply is the message that a Responder publishes in response to a
+	// Request. Its ID correlates it with the Request that it answers. If
+	// the Responder failed, Error describes the failure
+	Reply[Res any] struct {
+		ID      id.ID
+		Message Res
+		Error   string
+	}
+
+	// Error is returned by a Requester when a Responder reports that it
+	// failed to handle a Request
+	Error string
+)
+
+// Error messages
+const (
+	ErrRequesterClosed = "requester is closed"
+)
+
+func (e Error) Error() string {
+	return string(e)
+}
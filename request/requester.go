@@ -0,0 +1,171 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/caravan/essentials/broker"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+)
+
+type (
+	// Requester publishes Requests and waits for their correlated Replies
+	Requester[Req, Res any] interface {
+		closer.Closer
+
+		// Call publishes a Request for the message, and waits for its
+		// Reply until the Context is done. The Context's deadline, if it
+		// has one, is conveyed to the Responder
+		Call(ctx context.Context, req Req) (Res, error)
+	}
+
+	requester[Req, Res any] struct {
+		sync.Mutex
+		replyTo  string
+		producer topic.Producer[Request[Req]]
+		consumer topic.Consumer[Reply[Res]]
+		pending  map[id.ID]replyChannel[Res]
+		stopped  bool
+		closed   chan struct{}
+		done     chan struct{}
+	}
+
+	// replyChannel is the channel that a pending Call receives its Reply
+	// from
+	replyChannel[Res any] chan Reply[Res]
+)
+
+// MakeRequester returns a Requester that publishes to the named request Topic
+// and receives Replies from the named reply Topic. Topics that don't exist
+// are created with Consumed retention. Requesters can share a reply Topic, but
+// each of them receives every Reply that's published to it
+func MakeRequester[Req, Res any](
+	b *broker.Broker, requests, replies string,
+) (Requester[Req, Res], error) {
+	reqTopic, err := broker.Topic[Request[Req]](b, requests, config.Consumed)
+	if err != nil {
+		return nil, err
+	}
+	resTopic, err := broker.Topic[Reply[Res]](b, replies, config.Consumed)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &requester[Req, Res]{
+		replyTo:  replies,
+		producer: reqTopic.NewProducer(),
+		consumer: resTopic.NewConsumer(topic.StartAt(topic.Latest)),
+		pending:  map[id.ID]replyChannel[Res]{},
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go res.correlate()
+	return res, nil
+}
+
+func (r *requester[Req, Res]) Call(ctx context.Context, req Req) (Res, error) {
+	var zero Res
+	rID := id.New()
+	ch, err := r.await(rID)
+	if err != nil {
+		return zero, err
+	}
+	defer r.forget(rID)
+
+	msg := Request[Req]{
+		ID:      rID,
+		ReplyTo: r.replyTo,
+		Message: req,
+	}
+	if d, ok := ctx.Deadline(); ok {
+		msg.Deadline = d
+	}
+	if _, err := r.producer.Produce(msg); err != nil {
+		return zero, err
+	}
+
+	reply, err := message.ReceiveContext(ctx, ch)
+	switch {
+	case errors.Is(err, message.ErrClosed):
+		return zero, errors.New(ErrRequesterClosed)
+	case err != nil:
+		return zero, err
+	case reply.Error != "":
+		return zero, Error(reply.Error)
+	default:
+		return reply.Message, nil
+	}
+}
+
+// await registers a pending Call, returning the channel that its Reply will
+// be delivered to
+func (r *requester[_, Res]) await(i id.ID) (replyChannel[Res], error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.stopped || closer.IsClosed(r) {
+		return nil, errors.New(ErrRequesterClosed)
+	}
+	ch := make(replyChannel[Res], 1)
+	r.pending[i] = ch
+	return ch, nil
+}
+
+func (r *requester[_, _]) forget(i id.ID) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.pending, i)
+}
+
+// correlate delivers each Reply to the pending Call that it answers. Replies
+// that don't answer one of this Requester's pending Calls are discarded. Once
+// the Requester is closed, or its reply Topic is closed and drained, pending
+// Calls are abandoned
+func (r *requester[_, Res]) correlate() {
+	defer close(r.done)
+	for reply := range r.consumer.Receive() {
+		r.Lock()
+		if ch, ok := r.pending[reply.ID]; ok {
+			delete(r.pending, reply.ID)
+			ch <- reply
+		}
+		r.Unlock()
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.stopped = true
+	for i, ch := range r.pending {
+		delete(r.pending, i)
+		close(ch)
+	}
+}
+
+// Close closes the Requester. Pending Calls return an error
+func (r *requester[_, _]) Close() {
+	r.Lock()
+	select {
+	case <-r.closed:
+		r.Unlock()
+		return
+	default:
+		close(r.closed)
+	}
+	r.Unlock()
+
+	r.producer.Close()
+	r.consumer.Close()
+	<-r.done
+}
+
+func (r *requester[_, _]) IsClosed() <-chan struct{} {
+	return r.closed
+}
+
+func (r replyChannel[Res]) Receive() <-chan Reply[Res] {
+	return r
+}
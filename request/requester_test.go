@@ -0,0 +1,149 @@
+package request_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/broker"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func upper(_ context.Context, s string) (string, error) {
+	if s == "" {
+		return "", errors.New("nothing to convert")
+	}
+	return strings.ToUpper(s), nil
+}
+
+func TestRequestReply(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	resp, err := request.Respond(b, "upper", upper)
+	as.Nil(err)
+
+	req, err := request.MakeRequester[string, string](b, "upper", "upper.replies")
+	as.Nil(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	res, err := req.Call(ctx, "hello")
+	as.Nil(err)
+	as.Equal("HELLO", res)
+
+	_, err = req.Call(ctx, "")
+	as.EqualError(err, "nothing to convert")
+	as.Equal(request.Error("nothing to convert"), err)
+
+	req.Close()
+	resp.Close()
+	b.Close()
+}
+
+func TestRequestCorrelation(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	resp, err := request.Respond(b, "echo",
+		func(_ context.Context, i int) (string, error) {
+			return fmt.Sprint(i), nil
+		},
+	)
+	as.Nil(err)
+
+	// both Requesters receive every Reply, but only keep their own
+	first, _ := request.MakeRequester[int, string](b, "echo", "shared")
+	second, _ := request.MakeRequester[int, string](b, "echo", "shared")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			res, err := first.Call(ctx, i)
+			as.Nil(err)
+			as.Equal(fmt.Sprint(i), res)
+		}
+	}()
+	for i := 100; i < 150; i++ {
+		res, err := second.Call(ctx, i)
+		as.Nil(err)
+		as.Equal(fmt.Sprint(i), res)
+	}
+	<-done
+
+	first.Close()
+	second.Close()
+	resp.Close()
+	b.Close()
+}
+
+func TestRequestTimeout(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	req, err := request.MakeRequester[string, string](b, "nobody", "replies")
+	as.Nil(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = req.Call(ctx, "anyone?")
+	as.Equal(message.ErrTimedOut, err)
+	as.ErrorIs(err, context.DeadlineExceeded)
+
+	// a Responder that arrives later discards the expired Request
+	handled := make(chan string, 1)
+	resp, _ := request.Respond(b, "nobody",
+		func(_ context.Context, s string) (string, error) {
+			handled <- s
+			return s, nil
+		},
+	)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	res, err := req.Call(ctx, "hello")
+	as.Nil(err)
+	as.Equal("hello", res)
+	as.Equal("hello", <-handled)
+
+	req.Close()
+	resp.Close()
+	b.Close()
+}
+
+func TestRequesterClosed(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	req, err := request.MakeRequester[string, string](b, "nobody", "replies")
+	as.Nil(err)
+
+	errs := make(chan error)
+	go func() {
+		_, err := req.Call(context.Background(), "waiting")
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	req.Close()
+	as.EqualError(<-errs, request.ErrRequesterClosed)
+
+	_, err = req.Call(context.Background(), "closed")
+	as.EqualError(err, request.ErrRequesterClosed)
+	b.Close()
+}
+
+func TestRequesterTypeMismatch(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	_, err := broker.Create[int](b, "numbers")
+	as.Nil(err)
+
+	_, err = request.MakeRequester[string, string](b, "numbers", "replies")
+	as.NotNil(err)
+	_, err = request.Respond(b, "numbers", upper)
+	as.NotNil(err)
+	b.Close()
+}
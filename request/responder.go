@@ -0,0 +1,123 @@
+package request
+
+import (
+	"context"
+
+	"github.com/caravan/essentials/broker"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/clock"
+	"github.com/caravan/essentials/topic/config"
+)
+
+type (
+	// Handler produces the response to a Request's message. The Context is
+	// done when the Request's deadline passes or the Responder is closed
+	Handler[Req, Res any] func(ctx context.Context, req Req) (Res, error)
+
+	responder[Req, Res any] struct {
+		broker    *broker.Broker
+		handler   Handler[Req, Res]
+		consumer  topic.Consumer[Request[Req]]
+		producers map[string]topic.Producer[Reply[Res]]
+		clock     clock.Clock
+		ctx       context.Context
+		cancel    context.CancelFunc
+		done      chan struct{}
+	}
+)
+
+// Respond consumes Requests from the named request Topic, routing the result
+// of the Handler to the Topic that each Request names as its ReplyTo. The
+// request Topic is created with Consumed retention if it doesn't exist.
+// Requests are handled one at a time, so Responders that join a consumer
+// group using the ConsumerOptions can share the load. Requests whose
+// deadline has passed, according to the request Topic's Clock, are discarded,
+// as are Replies whose Topic no longer exists
+func Respond[Req, Res any](
+	b *broker.Broker, requests string, h Handler[Req, Res],
+	o ...topic.ConsumerOption,
+) (closer.Closer, error) {
+	reqTopic, err := broker.Topic[Request[Req]](b, requests, config.Consumed)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := b.Describe(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res := &responder[Req, Res]{
+		broker:    b,
+		handler:   h,
+		consumer:  reqTopic.NewConsumer(o...),
+		producers: map[string]topic.Producer[Reply[Res]]{},
+		clock:     desc.Config.Clock,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go res.start()
+	return res, nil
+}
+
+func (r *responder[Req, Res]) start() {
+	defer close(r.done)
+	defer func() {
+		for _, p := range r.producers {
+			p.Close()
+		}
+	}()
+	for req := range r.consumer.Receive() {
+		if !req.Deadline.IsZero() && !r.clock.Now().Before(req.Deadline) {
+			continue
+		}
+		r.reply(req.ReplyTo, r.handle(req))
+	}
+}
+
+func (r *responder[Req, Res]) handle(req Request[Req]) Reply[Res] {
+	ctx := r.ctx
+	if !req.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, req.Deadline)
+		defer cancel()
+	}
+	res := Reply[Res]{ID: req.ID}
+	msg, err := r.handler(ctx, req.Message)
+	if err != nil {
+		res.Error = err.Error()
+	} else {
+		res.Message = msg
+	}
+	return res
+}
+
+func (r *responder[_, Res]) reply(replyTo string, res Reply[Res]) {
+	if r.ctx.Err() != nil {
+		return
+	}
+	p, ok := r.producers[replyTo]
+	if !ok || closer.IsClosed(p) {
+		t, err := broker.Get[Reply[Res]](r.broker, replyTo)
+		if err != nil {
+			return
+		}
+		p = t.NewProducer()
+		r.producers[replyTo] = p
+	}
+	_, _ = p.Produce(res)
+}
+
+// Close stops the Responder. The Context of a Request being handled is done,
+// and any Reply to it is discarded
+func (r *responder[_, _]) Close() {
+	r.cancel()
+	r.consumer.Close()
+	<-r.done
+}
+
+func (r *responder[_, _]) IsClosed() <-chan struct{} {
+	return r.ctx.Done()
+}
@@ -0,0 +1,153 @@
+package request_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/broker"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/request"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/clock/clocktest"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponderDeadline(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	deadlines := make(chan bool, 1)
+	resp, err := request.Respond(b, "work",
+		func(ctx context.Context, s string) (string, error) {
+			_, ok := ctx.Deadline()
+			deadlines <- ok
+			return s, nil
+		},
+	)
+	as.Nil(err)
+
+	req, _ := request.MakeRequester[string, string](b, "work", "replies")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = req.Call(ctx, "value")
+	as.Nil(err)
+	as.True(<-deadlines)
+
+	_, err = req.Call(context.Background(), "value")
+	as.Nil(err)
+	as.False(<-deadlines)
+
+	req.Close()
+	resp.Close()
+	b.Close()
+}
+
+func TestResponderClock(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	now := time.Now()
+	_, err := broker.Create[request.Request[string]](b, "work",
+		config.Consumed, config.Clock(clocktest.Make(now.Add(time.Hour))),
+	)
+	as.Nil(err)
+
+	handled := make(chan string, 2)
+	resp, err := request.Respond(b, "work",
+		func(_ context.Context, s string) (string, error) {
+			handled <- s
+			return s, nil
+		},
+	)
+	as.Nil(err)
+
+	// the first deadline has passed by the request Topic's Clock
+	reqs, _ := broker.Get[request.Request[string]](b, "work")
+	p := reqs.NewProducer()
+	p.Send() <- request.Request[string]{
+		Message: "expired", Deadline: now.Add(time.Minute),
+	}
+	p.Send() <- request.Request[string]{Message: "handled"}
+	p.Close()
+
+	as.Equal("handled", <-handled)
+	resp.Close()
+	b.Close()
+}
+
+func TestResponderGroup(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	handler := func(name string) request.Handler[int, string] {
+		return func(context.Context, int) (string, error) {
+			return name, nil
+		}
+	}
+	first, _ := request.Respond(b, "jobs", handler("first"),
+		topic.Group("workers"),
+	)
+	second, _ := request.Respond(b, "jobs", handler("second"),
+		topic.Group("workers"),
+	)
+
+	req, _ := request.MakeRequester[int, string](b, "jobs", "replies")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	seen := map[string]int{}
+	for i := 0; i < 20; i++ {
+		res, err := req.Call(ctx, i)
+		as.Nil(err)
+		seen[res]++
+	}
+	as.Equal(20, seen["first"]+seen["second"])
+
+	req.Close()
+	first.Close()
+	second.Close()
+	b.Close()
+}
+
+func TestResponderClose(t *testing.T) {
+	as := assert.New(t)
+	b := broker.Make()
+	started := make(chan struct{})
+	resp, err := request.Respond(b, "slow",
+		func(ctx context.Context, s string) (string, error) {
+			close(started)
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	)
+	as.Nil(err)
+
+	reqs, _ := broker.Get[request.Request[string]](b, "slow")
+	p := reqs.NewProducer()
+	p.Send() <- request.Request[string]{Message: "forever"}
+	p.Close()
+	<-started
+
+	resp.Close()
+	as.True(closer.IsClosed(resp))
+
+	// Replies to Topics that don't exist are discarded
+	resp, _ = request.Respond(b, "slow",
+		func(_ context.Context, s string) (string, error) {
+			return s, nil
+		},
+	)
+	p = reqs.NewProducer()
+	p.Send() <- request.Request[string]{ReplyTo: "missing", Message: "lost"}
+	p.Close()
+
+	req, _ := request.MakeRequester[string, string](b, "slow", "replies")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	res, err := req.Call(ctx, "found")
+	as.Nil(err)
+	as.Equal("found", res)
+	as.Equal([]string{"replies", "slow"}, b.Names())
+
+	req.Close()
+	resp.Close()
+	b.Close()
+}
@@ -0,0 +1,117 @@
+package stream
+
+import "github.com/caravan/essentials/message"
+
+// Map starts a Stream that writes the result of calling the function with
+// each message received. The Stream stops if the function returns an error
+func Map[In, Out any](
+	r message.Receiver[In], s message.Sender[Out], fn func(In) (Out, error),
+) Stream {
+	return start(r, s, func(in In, emit func(Out) error) (bool, error) {
+		out, err := fn(in)
+		if err != nil {
+			return false, err
+		}
+		return true, emit(out)
+	})
+}
+
+// Filter starts a Stream that writes only the messages for which the
+// predicate returns true. The Stream stops if the predicate returns an error
+func Filter[Msg any](
+	r message.Receiver[Msg], s message.Sender[Msg],
+	pred func(Msg) (bool, error),
+) Stream {
+	return start(r, s, func(msg Msg, emit func(Msg) error) (bool, error) {
+		ok, err := pred(msg)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return true, nil
+		}
+		return true, emit(msg)
+	})
+}
+
+// FlatMap starts a Stream that writes each of the results of calling the
+// function with each message received, in order. The Stream stops if the
+// function returns an error
+func FlatMap[In, Out any](
+	r message.Receiver[In], s message.Sender[Out],
+	fn func(In) ([]Out, error),
+) Stream {
+	return start(r, s, func(in In, emit func(Out) error) (bool, error) {
+		res, err := fn(in)
+		if err != nil {
+			return false, err
+		}
+		for _, out := range res {
+			if err := emit(out); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// Take starts a Stream that writes the first n messages received, and then
+// stops
+func Take[Msg any](
+	r message.Receiver[Msg], s message.Sender[Msg], n int,
+) Stream {
+	if n <= 0 {
+		return stopped()
+	}
+	remaining := n
+	return start(r, s, func(msg Msg, emit func(Msg) error) (bool, error) {
+		if err := emit(msg); err != nil {
+			return false, err
+		}
+		remaining--
+		return remaining > 0, nil
+	})
+}
+
+// Skip starts a Stream that discards the first n messages received, and then
+// writes the rest
+func Skip[Msg any](
+	r message.Receiver[Msg], s message.Sender[Msg], n int,
+) Stream {
+	remaining := n
+	return start(r, s, func(msg Msg, emit func(Msg) error) (bool, error) {
+		if remaining > 0 {
+			remaining--
+			return true, nil
+		}
+		return true, emit(msg)
+	})
+}
+
+// Distinct starts a Stream that writes only the first occurrence of each
+// message received. Every distinct message is remembered for as long as the
+// Stream runs
+func Distinct[Msg comparable](
+	r message.Receiver[Msg], s message.Sender[Msg],
+) Stream {
+	return DistinctBy(r, s, func(msg Msg) Msg {
+		return msg
+	})
+}
+
+// DistinctBy starts a Stream that writes only the first message received for
+// each key returned by the function. Every distinct key is remembered for as
+// long as the Stream runs
+func DistinctBy[Msg any, Key comparable](
+	r message.Receiver[Msg], s message.Sender[Msg], key func(Msg) Key,
+) Stream {
+	seen := map[Key]struct{}{}
+	return start(r, s, func(msg Msg, emit func(Msg) error) (bool, error) {
+		k := key(msg)
+		if _, ok := seen[k]; ok {
+			return true, nil
+		}
+		seen[k] = struct{}{}
+		return true, emit(msg)
+	})
+}
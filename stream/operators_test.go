@@ -0,0 +1,178 @@
+package stream_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+// pipe is a channel that can be used as both a Sender and a Receiver
+type pipe[Msg any] chan Msg
+
+func (p pipe[Msg]) Send() chan<- Msg {
+	return p
+}
+
+func (p pipe[Msg]) Receive() <-chan Msg {
+	return p
+}
+
+func feed[Msg any](msgs ...Msg) pipe[Msg] {
+	res := make(pipe[Msg], len(msgs))
+	for _, m := range msgs {
+		res <- m
+	}
+	close(res)
+	return res
+}
+
+func collect[Msg any](s stream.Stream, out pipe[Msg]) ([]Msg, error) {
+	var res []Msg
+	for {
+		select {
+		case m := <-out:
+			res = append(res, m)
+		case <-s.IsClosed():
+			for {
+				select {
+				case m := <-out:
+					res = append(res, m)
+				default:
+					return res, s.Wait()
+				}
+			}
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	as := assert.New(t)
+	out := make(pipe[string])
+	s := stream.Map(feed(1, 2, 3), out, func(i int) (string, error) {
+		return strconv.Itoa(i * 2), nil
+	})
+	res, err := collect(s, out)
+	as.Nil(err)
+	as.Equal([]string{"2", "4", "6"}, res)
+}
+
+func TestMapError(t *testing.T) {
+	as := assert.New(t)
+	out := make(pipe[int])
+	in := feed("1", "two", "3")
+	s := stream.Map(in, out, strconv.Atoi)
+	res, err := collect(s, out)
+	as.Equal([]int{1}, res)
+	as.ErrorIs(err, strconv.ErrSyntax)
+
+	// the remaining messages are left unread
+	as.Equal("3", <-in)
+}
+
+func TestFilter(t *testing.T) {
+	as := assert.New(t)
+	out := make(pipe[int])
+	s := stream.Filter(feed(1, 2, 3, 4, 5, 6), out, func(i int) (bool, error) {
+		return i%2 == 0, nil
+	})
+	res, err := collect(s, out)
+	as.Nil(err)
+	as.Equal([]int{2, 4, 6}, res)
+
+	failed := errors.New("failed")
+	s = stream.Filter(feed(1, 2), out, func(int) (bool, error) {
+		return false, failed
+	})
+	_, err = collect(s, out)
+	as.Equal(failed, err)
+}
+
+func TestFlatMap(t *testing.T) {
+	as := assert.New(t)
+	out := make(pipe[string])
+	s := stream.FlatMap(feed("a b", "", "c"), out,
+		func(s string) ([]string, error) {
+			return strings.Fields(s), nil
+		},
+	)
+	res, err := collect(s, out)
+	as.Nil(err)
+	as.Equal([]string{"a", "b", "c"}, res)
+}
+
+func TestTake(t *testing.T) {
+	as := assert.New(t)
+	out := make(pipe[int])
+	in := feed(1, 2, 3, 4)
+	res, err := collect(stream.Take(in, out, 2), out)
+	as.Nil(err)
+	as.Equal([]int{1, 2}, res)
+	as.Equal(3, <-in)
+
+	res, err = collect(stream.Take(in, out, 0), out)
+	as.Nil(err)
+	as.Empty(res)
+	as.Equal(4, <-in)
+}
+
+func TestSkip(t *testing.T) {
+	as := assert.New(t)
+	out := make(pipe[int])
+	res, err := collect(stream.Skip(feed(1, 2, 3, 4), out, 3), out)
+	as.Nil(err)
+	as.Equal([]int{4}, res)
+}
+
+func TestDistinct(t *testing.T) {
+	as := assert.New(t)
+	out := make(pipe[string])
+	s := stream.Distinct(feed("a", "b", "a", "c", "b"), out)
+	res, err := collect(s, out)
+	as.Nil(err)
+	as.Equal([]string{"a", "b", "c"}, res)
+
+	s = stream.DistinctBy(feed("apple", "avocado", "banana"), out,
+		func(s string) byte {
+			return s[0]
+		},
+	)
+	res, err = collect(s, out)
+	as.Nil(err)
+	as.Equal([]string{"apple", "banana"}, res)
+}
+
+func TestBetweenTopics(t *testing.T) {
+	as := assert.New(t)
+	in := essentials.NewTopic[int]()
+	out := essentials.NewTopic[int]()
+	p := in.NewProducer()
+	for i := 1; i <= 5; i++ {
+		p.Send() <- i
+	}
+	p.Close()
+
+	c := in.NewConsumer()
+	op := out.NewProducer()
+	s := stream.Map(c, op, func(i int) (int, error) {
+		return i * i, nil
+	})
+
+	oc := out.NewConsumer()
+	for _, expected := range []int{1, 4, 9, 16, 25} {
+		as.Equal(expected, message.MustReceive[int](oc))
+	}
+
+	s.Close()
+	as.Nil(s.Wait())
+	oc.Close()
+	op.Close()
+	c.Close()
+	in.Close()
+	out.Close()
+}
@@ -0,0 +1,175 @@
+package stream
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+
+	internal "github.com/caravan/essentials/internal/topic"
+)
+
+type (
+	// Stream is a running operator that reads messages from a Receiver and
+	// writes the results to a Sender. A Stream stops when its Receiver's
+	// channel is closed, when the operator has nothing more to write, when
+	// the operator fails, or when the Stream is closed. Neither the
+	// Receiver nor the Sender are closed by the Stream
+	Stream interface {
+		closer.Closer
+
+		// Wait blocks until the Stream stops, returning the error that
+		// stopped it, if any
+		Wait() error
+	}
+
+	// Operator starts a Stream that writes to the provided Sender
+	Operator[Out any] func(message.Sender[Out]) Stream
+
+	// step processes a single message, emitting any results. It returns
+	// whether the Stream should continue reading messages
+	step[In, Out any] func(in In, emit func(Out) error) (bool, error)
+
+	stream struct {
+		once sync.Once
+		stop chan struct{}
+		done chan struct{}
+		err  error
+	}
+)
+
+// Error messages
+const (
+	ErrSenderClosed = "stream sender is closed"
+)
+
+// errStopped is returned by emit when the Stream is closed while sending
+var errStopped = errors.New("stream stopped")
+
+// Into instantiates a new Topic with the specified Options, and starts the
+// Operator writing to a Producer of that Topic. Once the Stream stops, the
+// Producer and the Topic are closed, allowing the Topic's Consumers to drain
+// what the Operator wrote to it. An error is returned if the Topic can't be
+// instantiated
+func Into[Msg any](
+	op Operator[Msg], o ...config.Option,
+) (topic.Topic[Msg], Stream, error) {
+	t, err := internal.Open[Msg](o...)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := t.NewProducer()
+	s := op(p)
+	res := makeStream()
+	go func() {
+		defer close(res.done)
+		select {
+		case <-s.IsClosed():
+		case <-res.stop:
+			s.Close()
+		}
+		res.err = s.Wait()
+		p.Close()
+		t.Close()
+	}()
+	return t, res, nil
+}
+
+func makeStream() *stream {
+	return &stream{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// start runs the step for each message received, until the Stream stops
+func start[In, Out any](
+	r message.Receiver[In], s message.Sender[Out], fn step[In, Out],
+) Stream {
+	res := makeStream()
+	go func() {
+		defer close(res.done)
+		res.err = pump(res, r, s, fn)
+	}()
+	return res
+}
+
+// stopped returns a Stream that has already stopped, without reading any
+// messages
+func stopped() Stream {
+	res := makeStream()
+	close(res.done)
+	return res
+}
+
+func pump[In, Out any](
+	st *stream, r message.Receiver[In], s message.Sender[Out],
+	fn step[In, Out],
+) error {
+	emit := func(msg Out) error {
+		return send(st, s, msg)
+	}
+	for {
+		select {
+		case <-st.stop:
+			return nil
+		case in, ok := <-r.Receive():
+			if !ok {
+				return nil
+			}
+			more, err := fn(in, emit)
+			if err == errStopped {
+				return nil
+			}
+			if err != nil || !more {
+				return err
+			}
+		}
+	}
+}
+
+// send writes a message to the Sender, unless the Stream is closed first. A
+// Sender that can be closed is checked so that its closing is reported
+func send[Msg any](st *stream, s message.Sender[Msg], msg Msg) (err error) {
+	defer func() {
+		// the Sender's channel was closed while sending
+		if recover() != nil {
+			err = errors.New(ErrSenderClosed)
+		}
+	}()
+
+	var closed <-chan struct{}
+	if c, ok := s.(closer.Closer); ok {
+		closed = c.IsClosed()
+	}
+	select {
+	case <-st.stop:
+		return errStopped
+	case <-closed:
+		return errors.New(ErrSenderClosed)
+	case s.Send() <- msg:
+		return nil
+	}
+}
+
+// Close stops the Stream, waiting for it to finish
+func (s *stream) Close() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+}
+
+// IsClosed returns a channel that is closed once the Stream stops
+func (s *stream) IsClosed() <-chan struct{} {
+	return s.done
+}
+
+// Wait blocks until the Stream stops, returning the error that stopped it
+func (s *stream) Wait() error {
+	<-s.done
+	return s.err
+}
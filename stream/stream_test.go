@@ -0,0 +1,110 @@
+package stream_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/stream"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInto(t *testing.T) {
+	as := assert.New(t)
+	in := essentials.NewTopic[string]()
+	p := in.NewProducer()
+	p.Send() <- "hello"
+	p.Send() <- "world"
+	p.Close()
+
+	c := in.NewConsumer()
+	top, s, err := stream.Into(func(out message.Sender[string]) stream.Stream {
+		return stream.Take(c, out, 2)
+	}, config.Permanent)
+	as.Nil(err)
+
+	as.Nil(s.Wait())
+	as.Equal(2, int(top.Length()))
+
+	oc := top.NewConsumer()
+	as.Equal("hello", message.MustReceive[string](oc))
+	as.Equal("world", message.MustReceive[string](oc))
+	_, ok := <-oc.Receive()
+	as.False(ok)
+	as.True(closer.IsClosed(top))
+	c.Close()
+	in.Close()
+}
+
+func TestIntoClose(t *testing.T) {
+	as := assert.New(t)
+	in := make(pipe[string])
+	top, s, err := stream.Into(func(out message.Sender[string]) stream.Stream {
+		return stream.Map(in, out, func(s string) (string, error) {
+			return strings.ToUpper(s), nil
+		})
+	})
+	as.Nil(err)
+	in <- "first"
+	as.Eventually(func() bool {
+		return top.Length() == 1
+	}, time.Second, time.Millisecond)
+
+	s.Close()
+	as.True(closer.IsClosed(s))
+	as.Nil(s.Wait())
+	as.True(closer.IsClosed(top))
+
+	c := top.NewConsumer()
+	as.Equal("FIRST", message.MustReceive[string](c))
+	_, ok := <-c.Receive()
+	as.False(ok)
+}
+
+func TestIntoInvalid(t *testing.T) {
+	as := assert.New(t)
+	top, s, err := stream.Into(func(out message.Sender[string]) stream.Stream {
+		as.Fail("operator should not have been started")
+		return nil
+	}, config.TTL(-1))
+	as.Nil(top)
+	as.Nil(s)
+	as.EqualError(err, config.ErrInvalidTTL)
+}
+
+func TestStreamClose(t *testing.T) {
+	as := assert.New(t)
+	in := make(pipe[int])
+	out := make(pipe[int])
+	s := stream.Skip(in, out, 0)
+	in <- 1
+
+	// the Stream is blocked sending, and closing it abandons the message
+	s.Close()
+	as.Nil(s.Wait())
+	as.True(closer.IsClosed(s))
+	s.Close()
+
+	select {
+	case <-out:
+		as.Fail("message should not have been sent")
+	default:
+	}
+}
+
+func TestStreamSenderClosed(t *testing.T) {
+	as := assert.New(t)
+	top := essentials.NewTopic[int]()
+	p := top.NewProducer()
+	p.Close()
+
+	s := stream.Map(feed(1), p, func(i int) (int, error) {
+		return i, nil
+	})
+	as.EqualError(s.Wait(), stream.ErrSenderClosed)
+	top.Close()
+}
@@ -0,0 +1,90 @@
+package topic
+
+import (
+	"time"
+
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/essentials/topic/backoff"
+)
+
+type (
+	// AckConsumer is a Consumer whose messages must be acknowledged. Any
+	// message that isn't acknowledged before its visibility timeout lapses,
+	// or that is negatively acknowledged, will be delivered again, unless
+	// it has expired by then. Messages are only considered consumed by a
+	// retention Policy once acknowledged
+	AckConsumer[Msg any] interface {
+		message.ClosingReceiver[Delivery[Msg]]
+		Identified
+		Seeker
+	}
+
+	// Delivery is a single delivery attempt of a message to an AckConsumer
+	Delivery[Msg any] interface {
+		// Message returns the message being delivered
+		Message() Msg
+
+		// Offset returns the Offset of the message within its Topic
+		Offset() Offset
+
+		// Attempt returns the number of times the message has been
+		// delivered, including this Delivery
+		Attempt() int
+
+		// ConsumerID returns the identifier of the receiving AckConsumer
+		ConsumerID() id.ID
+
+		// Ack acknowledges that the message was processed successfully
+		Ack()
+
+		// Nack reports that the message could not be processed. It will
+		// be delivered again after a delay determined by the Consumer's
+		// redelivery backoff sequence
+		Nack(error)
+	}
+
+	// DeadLetter is a message that an AckConsumer gave up on delivering,
+	// along with the circumstances of its final delivery attempt. LastError
+	// describes why that attempt failed, and is stored as a string so that
+	// a persistent dead-letter Topic can be reopened
+	DeadLetter[Msg any] struct {
+		Message    Msg
+		Offset     Offset
+		Attempts   int
+		LastError  string
+		ConsumerID id.ID
+	}
+)
+
+// Defaults
+const (
+	DefaultVisibilityTimeout = 30 * time.Second
+)
+
+// Error messages
+const (
+	ErrVisibilityTimeout = "message was not acknowledged before its visibility timeout"
+)
+
+// DefaultRedelivery is the backoff Generator used to space the redelivery of
+// negatively acknowledged messages if none is specified
+var DefaultRedelivery = backoff.MakeFibonacciGenerator(
+	10*time.Millisecond, 10*time.Second,
+)
+
+// VisibilityTimeout configures how long an AckConsumer waits for a delivered
+// message to be acknowledged before delivering it again
+func VisibilityTimeout(d time.Duration) ConsumerOption {
+	return func(c *ConsumerConfig) {
+		c.VisibilityTimeout = d
+	}
+}
+
+// Redelivery configures the backoff Generator that an AckConsumer uses to
+// space the redelivery of a negatively acknowledged message
+func Redelivery(b backoff.Generator) ConsumerOption {
+	return func(c *ConsumerConfig) {
+		c.Redelivery = b
+	}
+}
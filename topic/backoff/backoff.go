@@ -51,3 +51,28 @@ func MakeFibonacciGenerator(unit, max time.Duration) Generator {
 		}
 	}
 }
+
+// MakeExponentialGenerator creates an exponential generator for retrying at
+// intervals that begin with the base Duration and grow by the specified
+// multiplier, up to a maximum Duration
+func MakeExponentialGenerator(
+	base time.Duration, multiplier float64, max time.Duration,
+) Generator {
+	return func() Next {
+		return exponential(base, multiplier, max)
+	}
+}
+
+func exponential(
+	base time.Duration, multiplier float64, max time.Duration,
+) Next {
+	curr := float64(base)
+	return func() time.Duration {
+		if curr >= float64(max) {
+			return max
+		}
+		res := time.Duration(curr)
+		curr *= multiplier
+		return res
+	}
+}
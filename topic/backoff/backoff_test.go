@@ -40,3 +40,81 @@ func TestFibonacci(t *testing.T) {
 	as.Equal(time.Millisecond*5, next())
 	as.Equal(time.Millisecond*5, next())
 }
+
+func TestExponential(t *testing.T) {
+	as := assert.New(t)
+	next := backoff.MakeExponentialGenerator(
+		time.Millisecond, 2, time.Millisecond*10,
+	)()
+	as.Equal(time.Millisecond*1, next())
+	as.Equal(time.Millisecond*2, next())
+	as.Equal(time.Millisecond*4, next())
+	as.Equal(time.Millisecond*8, next())
+
+	// Should keep providing 10 indefinitely
+	as.Equal(time.Millisecond*10, next())
+	as.Equal(time.Millisecond*10, next())
+}
+
+func TestFullJitter(t *testing.T) {
+	as := assert.New(t)
+	gen := backoff.MakeFullJitterGenerator(
+		time.Millisecond, 2, time.Millisecond*10, backoff.MakeSource(42),
+	)
+	next := gen()
+	for _, limit := range []time.Duration{1, 2, 4, 8, 10, 10} {
+		d := next()
+		as.GreaterOrEqual(d, time.Duration(0))
+		as.LessOrEqual(d, time.Millisecond*limit)
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	as := assert.New(t)
+	gen := backoff.MakeEqualJitterGenerator(
+		time.Millisecond, 2, time.Millisecond*10, backoff.MakeSource(42),
+	)
+	next := gen()
+	for _, limit := range []time.Duration{1, 2, 4, 8, 10, 10} {
+		d := next()
+		as.GreaterOrEqual(d, time.Millisecond*limit/2)
+		as.LessOrEqual(d, time.Millisecond*limit)
+	}
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	as := assert.New(t)
+	gen := backoff.MakeDecorrelatedJitterGenerator(
+		time.Millisecond, time.Millisecond*10, backoff.MakeSource(42),
+	)
+	next := gen()
+	prev := time.Millisecond
+	for i := 0; i < 20; i++ {
+		d := next()
+		as.GreaterOrEqual(d, time.Millisecond)
+		as.LessOrEqual(d, min(time.Millisecond*10, prev*3))
+		prev = d
+	}
+}
+
+func TestJitterSource(t *testing.T) {
+	as := assert.New(t)
+	sequence := func(seed uint64) []time.Duration {
+		next := backoff.MakeFullJitterGenerator(
+			time.Millisecond, 2, time.Second, backoff.MakeSource(seed),
+		)()
+		res := make([]time.Duration, 10)
+		for i := range res {
+			res[i] = next()
+		}
+		return res
+	}
+	as.Equal(sequence(42), sequence(42))
+	as.NotEqual(sequence(42), sequence(43))
+
+	// without a Source, the top-level random Source is used
+	next := backoff.MakeFullJitterGenerator(
+		time.Millisecond, 2, time.Second, nil,
+	)()
+	as.LessOrEqual(next(), time.Millisecond)
+}
@@ -0,0 +1,91 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// random produces the random Durations of the jittered Generators. Because a
+// Generator's sequences can be advanced by many routines at once, a provided
+// Source is guarded by a mutex
+type random struct {
+	sync.Mutex
+	rand *rand.Rand
+}
+
+// MakeSource returns a seeded random Source, so that the sequences of
+// jittered Generators can be reproduced
+func MakeSource(seed uint64) rand.Source {
+	return rand.NewPCG(seed, seed)
+}
+
+// MakeFullJitterGenerator creates a generator for retrying at random intervals
+// between zero and the exponentially growing Duration that would otherwise be
+// produced by an exponential generator. If the Source is nil, the top-level
+// random Source is used
+func MakeFullJitterGenerator(
+	base time.Duration, multiplier float64, max time.Duration, src rand.Source,
+) Generator {
+	r := makeRandom(src)
+	return func() Next {
+		next := exponential(base, multiplier, max)
+		return func() time.Duration {
+			return r.between(0, next())
+		}
+	}
+}
+
+// MakeEqualJitterGenerator creates a generator for retrying at intervals that
+// are half of the exponentially growing Duration, plus a random amount of up
+// to the other half. If the Source is nil, the top-level random Source is used
+func MakeEqualJitterGenerator(
+	base time.Duration, multiplier float64, max time.Duration, src rand.Source,
+) Generator {
+	r := makeRandom(src)
+	return func() Next {
+		next := exponential(base, multiplier, max)
+		return func() time.Duration {
+			d := next()
+			return d/2 + r.between(0, d-d/2)
+		}
+	}
+}
+
+// MakeDecorrelatedJitterGenerator creates a generator for retrying at random
+// intervals between the base Duration and three times the previous interval,
+// up to a maximum Duration. If the Source is nil, the top-level random Source
+// is used
+func MakeDecorrelatedJitterGenerator(
+	base, max time.Duration, src rand.Source,
+) Generator {
+	r := makeRandom(src)
+	return func() Next {
+		prev := base
+		return func() time.Duration {
+			prev = min(max, r.between(base, prev*3))
+			return prev
+		}
+	}
+}
+
+func makeRandom(src rand.Source) *random {
+	if src == nil {
+		return &random{}
+	}
+	return &random{rand: rand.New(src)}
+}
+
+// between returns a random Duration in the closed interval [lo, hi]
+func (r *random) between(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	n := int64(hi-lo) + 1
+	if r.rand == nil {
+		return lo + time.Duration(rand.Int64N(n))
+	}
+	r.Lock()
+	defer r.Unlock()
+	return lo + time.Duration(r.rand.Int64N(n))
+}
@@ -0,0 +1,70 @@
+package clock
+
+import "time"
+
+type (
+	// Clock is the source of the current Time and of the Timers that a
+	// Topic uses for its timed behavior, such as retention, backoff, and
+	// redelivery. It can be replaced in order to test that behavior
+	// deterministically
+	Clock interface {
+		// Now returns the current Time
+		Now() time.Time
+
+		// NewTimer returns a Timer that expires after the specified
+		// Duration
+		NewTimer(time.Duration) Timer
+	}
+
+	// Timer delivers the Time to its channel once it expires
+	Timer interface {
+		// C returns the channel that receives the Time of expiration
+		C() <-chan time.Time
+
+		// Reset restarts the Timer so that it expires after the specified
+		// Duration. An expiration from before the Reset is not delivered
+		Reset(time.Duration)
+
+		// Stop prevents the Timer from expiring
+		Stop()
+	}
+
+	system struct{}
+
+	systemTimer struct {
+		timer *time.Timer
+	}
+)
+
+// System is the Clock backed by the operating system
+var System Clock = system{}
+
+func (system) Now() time.Time {
+	return time.Now()
+}
+
+func (system) NewTimer(d time.Duration) Timer {
+	return &systemTimer{
+		timer: time.NewTimer(d),
+	}
+}
+
+func (t *systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Reset discards an expiration that hasn't been received, rather than relying
+// on the timer semantics of newer Go releases to do so
+func (t *systemTimer) Reset(d time.Duration) {
+	if !t.timer.Stop() {
+		select {
+		case <-t.timer.C:
+		default:
+		}
+	}
+	t.timer.Reset(d)
+}
+
+func (t *systemTimer) Stop() {
+	t.timer.Stop()
+}
@@ -0,0 +1,125 @@
+package clocktest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/caravan/essentials/topic/clock"
+)
+
+type (
+	// Clock is a clock.Clock whose Time only changes when it is advanced
+	// manually. Its Timers expire when the Clock is advanced past their
+	// deadlines
+	Clock struct {
+		sync.Mutex
+		now    time.Time
+		timers map[*timer]struct{}
+	}
+
+	timer struct {
+		clock    *Clock
+		deadline time.Time
+		channel  chan time.Time
+	}
+)
+
+// Make returns a new Clock that starts at the specified Time
+func Make(start time.Time) *Clock {
+	return &Clock{
+		now:    start,
+		timers: map[*timer]struct{}{},
+	}
+}
+
+// Now returns the Clock's current Time
+func (c *Clock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+// NewTimer returns a Timer that expires once the Clock has been advanced by
+// at least the specified Duration
+func (c *Clock) NewTimer(d time.Duration) clock.Timer {
+	t := &timer{
+		clock:   c,
+		channel: make(chan time.Time, 1),
+	}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the Clock forward by the specified Duration, expiring any
+// Timers whose deadlines have been reached
+func (c *Clock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set moves the Clock to the specified Time, expiring any Timers whose
+// deadlines have been reached
+func (c *Clock) Set(t time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	c.set(t)
+}
+
+// Timers returns the number of Timers that have yet to expire. It can be used
+// to wait for a routine to begin waiting on the Clock
+func (c *Clock) Timers() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.timers)
+}
+
+func (c *Clock) set(now time.Time) {
+	c.now = now
+	for t := range c.timers {
+		if !t.deadline.After(now) {
+			delete(c.timers, t)
+			t.expire(now)
+		}
+	}
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.channel
+}
+
+func (t *timer) Reset(d time.Duration) {
+	c := t.clock
+	c.Lock()
+	defer c.Unlock()
+	t.drain()
+	delete(c.timers, t)
+	t.deadline = c.now.Add(d)
+	if d <= 0 {
+		t.expire(c.now)
+		return
+	}
+	c.timers[t] = struct{}{}
+}
+
+func (t *timer) Stop() {
+	c := t.clock
+	c.Lock()
+	defer c.Unlock()
+	delete(c.timers, t)
+	t.drain()
+}
+
+func (t *timer) expire(now time.Time) {
+	select {
+	case t.channel <- now:
+	default:
+	}
+}
+
+func (t *timer) drain() {
+	select {
+	case <-t.channel:
+	default:
+	}
+}
@@ -0,0 +1,63 @@
+package clocktest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/topic/clock/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	as := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	clk := clocktest.Make(start)
+	as.Equal(start, clk.Now())
+
+	clk.Advance(time.Minute)
+	as.Equal(start.Add(time.Minute), clk.Now())
+
+	later := time.Unix(5000, 0)
+	clk.Set(later)
+	as.Equal(later, clk.Now())
+}
+
+func TestTimer(t *testing.T) {
+	as := assert.New(t)
+
+	clk := clocktest.Make(time.Unix(0, 0))
+	timer := clk.NewTimer(time.Second)
+	as.Equal(1, clk.Timers())
+
+	clk.Advance(500 * time.Millisecond)
+	select {
+	case <-timer.C():
+		as.Fail("Timer should not have expired")
+	default:
+	}
+
+	clk.Advance(500 * time.Millisecond)
+	as.Equal(time.Unix(1, 0), <-timer.C())
+	as.Equal(0, clk.Timers())
+
+	// an expiration from before a Reset is discarded
+	clk.Advance(time.Second)
+	timer.Reset(0)
+	clk.Advance(time.Second)
+	timer.Reset(time.Second)
+	select {
+	case <-timer.C():
+		as.Fail("Timer should have been reset")
+	default:
+	}
+
+	timer.Stop()
+	as.Equal(0, clk.Timers())
+	clk.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		as.Fail("Timer should have been stopped")
+	default:
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"math/rand/v2"
 	"time"
 
 	"github.com/caravan/essentials/topic/backoff"
@@ -10,6 +11,7 @@ import (
 // Error messages
 const (
 	ErrBackoffAlreadySet = "backoff algorithm already set in topic"
+	ErrInvalidBackoff    = "backoff base or multiplier is invalid"
 )
 
 // FixedBackoffSequence configures a Topic with a Generator wherein every
@@ -31,6 +33,68 @@ func FibonacciBackoffSequence(unit, max time.Duration) Option {
 	}
 }
 
+// ExponentialBackoffSequence creates a Generator wherein every Duration grows
+// by the specified multiplier, starting with the base Duration, up to the
+// specified maximum duration
+func ExponentialBackoffSequence(
+	base time.Duration, multiplier float64, max time.Duration,
+) Option {
+	return func(c *Config) error {
+		if err := checkExponential(base, multiplier); err != nil {
+			return err
+		}
+		gen := backoff.MakeExponentialGenerator(base, multiplier, max)
+		return maybeSetBackoffGenerator(c, gen)
+	}
+}
+
+// FullJitterBackoffSequence creates a Generator wherein every Duration is
+// random, between zero and what the exponential sequence would produce. If
+// the Source is nil, the top-level random Source is used
+func FullJitterBackoffSequence(
+	base time.Duration, multiplier float64, max time.Duration, src rand.Source,
+) Option {
+	return func(c *Config) error {
+		if err := checkExponential(base, multiplier); err != nil {
+			return err
+		}
+		gen := backoff.MakeFullJitterGenerator(base, multiplier, max, src)
+		return maybeSetBackoffGenerator(c, gen)
+	}
+}
+
+// EqualJitterBackoffSequence creates a Generator wherein every Duration is
+// half of what the exponential sequence would produce, plus a random amount of
+// up to the other half. If the Source is nil, the top-level random Source is
+// used
+func EqualJitterBackoffSequence(
+	base time.Duration, multiplier float64, max time.Duration, src rand.Source,
+) Option {
+	return func(c *Config) error {
+		if err := checkExponential(base, multiplier); err != nil {
+			return err
+		}
+		gen := backoff.MakeEqualJitterGenerator(base, multiplier, max, src)
+		return maybeSetBackoffGenerator(c, gen)
+	}
+}
+
+// DecorrelatedJitterBackoffSequence creates a Generator wherein every Duration
+// is random, between the base Duration and three times the previous one, up to
+// the specified maximum duration. If the Source is nil, the top-level random
+// Source is used
+func DecorrelatedJitterBackoffSequence(
+	base, max time.Duration, src rand.Source,
+) Option {
+	return func(c *Config) error {
+		if err := checkExponential(base, 1); err != nil {
+			return err
+		}
+		gen := backoff.MakeDecorrelatedJitterGenerator(base, max, src)
+		return maybeSetBackoffGenerator(c, gen)
+	}
+}
+
 // BackoffGenerator applies a provided backoff Generator to the Topic
 func BackoffGenerator(b backoff.Generator) Option {
 	return func(t *Config) error {
@@ -45,3 +109,10 @@ func maybeSetBackoffGenerator(c *Config, b backoff.Generator) error {
 	}
 	return errors.New(ErrBackoffAlreadySet)
 }
+
+func checkExponential(base time.Duration, multiplier float64) error {
+	if base <= 0 || multiplier < 1 {
+		return errors.New(ErrInvalidBackoff)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package config_test
 
 import (
+	"math/rand/v2"
 	"testing"
 	"time"
 
@@ -39,3 +40,66 @@ func TestBackoffGeneratorOption(t *testing.T) {
 		}),
 	).NewConsumer()
 }
+
+func TestExponentialBackoffOptions(t *testing.T) {
+	as := assert.New(t)
+
+	for _, o := range []config.Option{
+		config.ExponentialBackoffSequence(time.Microsecond, 2, time.Second),
+		config.FullJitterBackoffSequence(
+			time.Microsecond, 2, time.Second, nil,
+		),
+		config.EqualJitterBackoffSequence(
+			time.Microsecond, 2, time.Second, nil,
+		),
+		config.DecorrelatedJitterBackoffSequence(
+			time.Microsecond, time.Second, nil,
+		),
+	} {
+		cfg := &config.Config{}
+		as.Nil(config.ApplyOptions(cfg, o))
+		next := cfg.BackoffGenerator()
+		as.LessOrEqual(next(), time.Second)
+	}
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.ExponentialBackoffSequence(time.Microsecond, 0.5, 1),
+		), config.ErrInvalidBackoff,
+	)
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.DecorrelatedJitterBackoffSequence(0, time.Second, nil),
+		), config.ErrInvalidBackoff,
+	)
+}
+
+func TestJitterBackoffSource(t *testing.T) {
+	as := assert.New(t)
+
+	for _, o := range []func(src rand.Source) config.Option{
+		func(src rand.Source) config.Option {
+			return config.FullJitterBackoffSequence(
+				time.Millisecond, 2, time.Second, src,
+			)
+		},
+		func(src rand.Source) config.Option {
+			return config.EqualJitterBackoffSequence(
+				time.Millisecond, 2, time.Second, src,
+			)
+		},
+		func(src rand.Source) config.Option {
+			return config.DecorrelatedJitterBackoffSequence(
+				time.Millisecond, time.Second, src,
+			)
+		},
+	} {
+		sequence := func() []time.Duration {
+			cfg := &config.Config{}
+			as.Nil(config.ApplyOptions(cfg, o(backoff.MakeSource(42))))
+			next := cfg.BackoffGenerator()
+			return []time.Duration{next(), next(), next(), next()}
+		}
+		as.Equal(sequence(), sequence())
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/caravan/essentials/topic/overflow"
+)
+
+// Error messages
+const (
+	ErrMaxMessagesAlreadySet = "maximum message count already set in topic"
+	ErrMaxBytesAlreadySet    = "maximum byte count already set in topic"
+	ErrOverflowAlreadySet    = "overflow strategy already set in topic"
+	ErrInvalidBound          = "topic bounds must be greater than zero"
+	ErrMaxMessagesTooSmall   = "a blocking topic's maximum message count " +
+		"must be at least its segment increment"
+	ErrMaxBytesBlocking = "a blocking topic can't be bounded by bytes"
+)
+
+// MaxMessages bounds the number of messages that a Topic will retain. What
+// happens when the Topic is full is determined by its overflow Strategy
+func MaxMessages(n uint64) Option {
+	return func(c *Config) error {
+		if c.MaxMessages != 0 {
+			return errors.New(ErrMaxMessagesAlreadySet)
+		}
+		if n == 0 {
+			return errors.New(ErrInvalidBound)
+		}
+		c.MaxMessages = n
+		return nil
+	}
+}
+
+// MaxBytes bounds the number of bytes that a Topic will retain. Messages are
+// measured by the Topic's Sizer, which defaults to topic.DefaultSizer. A
+// byte bound requires an overflow Strategy other than overflow.Block
+func MaxBytes(n uint64) Option {
+	return func(c *Config) error {
+		if c.MaxBytes != 0 {
+			return errors.New(ErrMaxBytesAlreadySet)
+		}
+		if n == 0 {
+			return errors.New(ErrInvalidBound)
+		}
+		c.MaxBytes = n
+		return nil
+	}
+}
+
+// Overflow applies the Strategy used when a bounded Topic is full. If not
+// specified, overflow.Block is used
+func Overflow(s overflow.Strategy) Option {
+	return func(c *Config) error {
+		if c.Overflow != 0 {
+			return errors.New(ErrOverflowAlreadySet)
+		}
+		if s < overflow.Block || s > overflow.Fail {
+			return fmt.Errorf(overflow.ErrUnknownStrategy, s)
+		}
+		c.Overflow = s
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/overflow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundsConflict(t *testing.T) {
+	as := assert.New(t)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.MaxMessages(64), config.MaxMessages(128),
+		), config.ErrMaxMessagesAlreadySet,
+	)
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.MaxBytes(64), config.MaxBytes(128),
+		), config.ErrMaxBytesAlreadySet,
+	)
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.Overflow(overflow.Fail), config.Overflow(overflow.Block),
+		), config.ErrOverflowAlreadySet,
+	)
+}
+
+func TestBoundsInvalid(t *testing.T) {
+	as := assert.New(t)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{}, config.MaxMessages(0)),
+		config.ErrInvalidBound,
+	)
+	as.EqualError(
+		config.ApplyOptions(&config.Config{}, config.MaxBytes(0)),
+		config.ErrInvalidBound,
+	)
+	as.Error(
+		config.ApplyOptions(&config.Config{}, config.Overflow(42)),
+	)
+}
+
+func TestBoundsDefaults(t *testing.T) {
+	as := assert.New(t)
+	cfg := &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.Defaults))
+	as.Equal(config.DefaultOverflow, cfg.Overflow)
+
+	top := essentials.NewTopic[any](
+		config.MaxMessages(64), config.MaxBytes(1024),
+		config.Overflow(overflow.Fail),
+	)
+	as.NotNil(top)
+	top.Close()
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/caravan/essentials/topic/clock"
+)
+
+// Error messages
+const (
+	ErrClockAlreadySet = "clock already set in topic"
+)
+
+// Clock applies a provided Clock to the Topic. If not specified, the Topic
+// uses clock.System
+func Clock(clk clock.Clock) Option {
+	return func(c *Config) error {
+		return maybeSetClock(c, clk)
+	}
+}
+
+func maybeSetClock(c *Config, clk clock.Clock) error {
+	if c.Clock == nil {
+		c.Clock = clk
+		return nil
+	}
+	return errors.New(ErrClockAlreadySet)
+}
@@ -0,0 +1,26 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/caravan/essentials/topic/clock"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockConflict(t *testing.T) {
+	as := assert.New(t)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.Clock(clock.System), config.Clock(clock.System),
+		), config.ErrClockAlreadySet,
+	)
+}
+
+func TestClockDefault(t *testing.T) {
+	as := assert.New(t)
+	cfg := &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.Defaults))
+	as.Equal(clock.System, cfg.Clock)
+}
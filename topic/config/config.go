@@ -1,8 +1,16 @@
 package config
 
 import (
+	"time"
+
+	"github.com/caravan/essentials/topic"
 	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/clock"
+	"github.com/caravan/essentials/topic/overflow"
+	"github.com/caravan/essentials/topic/partition"
+	"github.com/caravan/essentials/topic/priority"
 	"github.com/caravan/essentials/topic/retention"
+	"github.com/caravan/essentials/topic/storage"
 )
 
 type (
@@ -12,6 +20,22 @@ type (
 		RetentionPolicy  retention.Policy
 		BackoffGenerator backoff.Generator
 		SegmentIncrement uint16
+		StoragePath      string
+		StorageCodec     storage.Codec
+		StorageSync      storage.SyncPolicy
+		Partitioner      partition.Partitioner
+		Scheduler        priority.Scheduler
+		MaxMessages      uint64
+		MaxBytes         uint64
+		Overflow         overflow.Strategy
+		Clock            clock.Clock
+		Sizer            topic.Sizer
+		TTL              time.Duration
+
+		// DeadLetterTopic is a topic.Topic of topic.DeadLetter values for
+		// the configured Topic's message type
+		DeadLetterTopic     any
+		MaxDeliveryAttempts int
 	}
 
 	// Option applies an option to a topic configuration instance
@@ -21,4 +45,6 @@ type (
 // Defaults
 const (
 	DefaultSegmentIncrement = 32
+	DefaultStorageSync      = storage.SyncSegment
+	DefaultOverflow         = overflow.Block
 )
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/caravan/essentials/topic"
+)
+
+// Error messages
+const (
+	ErrDeadLetterAlreadySet   = "dead-letter topic already set in topic"
+	ErrInvalidMaxAttempts     = "dead-letter max attempts must be at least one"
+	ErrDeadLetterTypeMismatch = "dead-letter topic does not accept this topic's messages"
+)
+
+// DeadLetter pairs a Topic with a dead-letter Topic. Any message that an
+// AckConsumer has delivered the specified maximum number of times without
+// it being acknowledged is moved to the dead-letter Topic instead of being
+// delivered again
+func DeadLetter[Msg any](
+	t topic.Topic[topic.DeadLetter[Msg]], maxAttempts int,
+) Option {
+	return func(c *Config) error {
+		if c.DeadLetterTopic != nil {
+			return errors.New(ErrDeadLetterAlreadySet)
+		}
+		if maxAttempts < 1 {
+			return errors.New(ErrInvalidMaxAttempts)
+		}
+		c.DeadLetterTopic = t
+		c.MaxDeliveryAttempts = maxAttempts
+		return nil
+	}
+}
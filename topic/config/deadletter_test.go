@@ -0,0 +1,35 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetterConflict(t *testing.T) {
+	as := assert.New(t)
+	dlq := essentials.NewTopic[topic.DeadLetter[any]]()
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.DeadLetter(dlq, 3), config.DeadLetter(dlq, 3),
+		), config.ErrDeadLetterAlreadySet,
+	)
+	as.EqualError(
+		config.ApplyOptions(&config.Config{}, config.DeadLetter(dlq, 0)),
+		config.ErrInvalidMaxAttempts,
+	)
+}
+
+func TestDeadLetterTypeMismatch(t *testing.T) {
+	as := assert.New(t)
+	dlq := essentials.NewTopic[topic.DeadLetter[string]]()
+
+	defer func() {
+		as.EqualError(recover().(error), config.ErrDeadLetterTypeMismatch)
+	}()
+	essentials.NewTopic[int](config.DeadLetter(dlq, 3))
+}
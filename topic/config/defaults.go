@@ -1,8 +1,13 @@
 package config
 
 import (
+	"github.com/caravan/essentials/topic"
 	"github.com/caravan/essentials/topic/backoff"
+	"github.com/caravan/essentials/topic/clock"
+	"github.com/caravan/essentials/topic/partition"
+	"github.com/caravan/essentials/topic/priority"
 	"github.com/caravan/essentials/topic/retention"
+	"github.com/caravan/essentials/topic/storage"
 )
 
 // ApplyDefaults copies a Config instance and applies defaults to it
@@ -17,9 +22,44 @@ func ApplyDefaults(c *Config) *Config {
 	if res.SegmentIncrement == 0 {
 		res.SegmentIncrement = DefaultSegmentIncrement
 	}
+	if res.StorageCodec == nil {
+		res.StorageCodec = storage.JSON
+	}
+	if res.StorageSync == 0 {
+		res.StorageSync = DefaultStorageSync
+	}
+	if res.Overflow == 0 {
+		res.Overflow = DefaultOverflow
+	}
+	if res.Partitioner == nil {
+		res.Partitioner = partition.Hash
+	}
+	if res.Scheduler == nil {
+		res.Scheduler = priority.Strict
+	}
+	if res.Clock == nil {
+		res.Clock = clock.System
+	}
+	if res.Sizer == nil && (res.MaxBytes != 0 || isSized(res.RetentionPolicy)) {
+		res.Sizer = topic.DefaultSizer
+	}
 	return &res
 }
 
+// isSized returns whether a Policy is, or is composed of, a SizedPolicy
+func isSized(p retention.Policy) bool {
+	switch p := p.(type) {
+	case retention.SizedPolicy:
+		return true
+	case retention.UnaryPolicy:
+		return isSized(p.Policy())
+	case retention.BinaryPolicy:
+		return isSized(p.Left()) || isSized(p.Right())
+	default:
+		return false
+	}
+}
+
 // ApplyOptions applies Options to a topic
 func ApplyOptions(c *Config, options ...Option) error {
 	for _, o := range options {
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/caravan/essentials/topic/partition"
+)
+
+// Error messages
+const (
+	ErrPartitionerAlreadySet = "partitioner already set in topic"
+)
+
+// RoundRobin applies a round-robin Partitioner to a PartitionedTopic
+func RoundRobin(c *Config) error {
+	return maybeSetPartitioner(c, partition.MakeRoundRobin())
+}
+
+// Partitioner applies a provided Partitioner to a PartitionedTopic. If not
+// specified, messages are routed using partition.Hash
+func Partitioner(p partition.Partitioner) Option {
+	return func(c *Config) error {
+		return maybeSetPartitioner(c, p)
+	}
+}
+
+func maybeSetPartitioner(c *Config, p partition.Partitioner) error {
+	if c.Partitioner == nil {
+		c.Partitioner = p
+		return nil
+	}
+	return errors.New(ErrPartitionerAlreadySet)
+}
@@ -0,0 +1,31 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/partition"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionerConflict(t *testing.T) {
+	as := assert.New(t)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.RoundRobin, config.Partitioner(partition.Hash),
+		), config.ErrPartitionerAlreadySet,
+	)
+}
+
+func TestPartitionerDefault(t *testing.T) {
+	as := assert.New(t)
+	cfg := &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.Defaults))
+	as.NotNil(cfg.Partitioner)
+
+	top := essentials.NewPartitionedTopic[any](2, config.RoundRobin)
+	as.Equal(2, len(top.Partitions()))
+	top.Close()
+}
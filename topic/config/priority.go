@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/caravan/essentials/topic/priority"
+)
+
+// Error messages
+const (
+	ErrSchedulerAlreadySet = "priority scheduler already set in topic"
+	ErrInvalidWeight       = "priority weights must be greater than zero"
+	ErrInvalidAging        = "priority aging interval must be greater than zero"
+)
+
+// StrictPriority applies a strict priority Scheduler to a PriorityTopic,
+// starving lower priority levels for as long as higher priority messages are
+// waiting
+func StrictPriority(c *Config) error {
+	return maybeSetScheduler(c, priority.Strict)
+}
+
+// WeightedFair applies a weighted fair Scheduler to a PriorityTopic. Weights
+// are specified from highest to lowest priority, and levels without a
+// specified weight are given a weight of one
+func WeightedFair(weights ...int) Option {
+	return func(c *Config) error {
+		for _, w := range weights {
+			if w <= 0 {
+				return errors.New(ErrInvalidWeight)
+			}
+		}
+		return maybeSetScheduler(c, priority.MakeWeightedFair(weights...))
+	}
+}
+
+// Aging applies an aging Scheduler to a PriorityTopic. Waiting messages are
+// promoted by one level for every interval that they've waited
+func Aging(interval time.Duration) Option {
+	return func(c *Config) error {
+		if interval <= 0 {
+			return errors.New(ErrInvalidAging)
+		}
+		return maybeSetScheduler(c, priority.MakeAging(interval))
+	}
+}
+
+// PriorityScheduler applies a provided Scheduler to a PriorityTopic. If not
+// specified, messages are delivered using priority.Strict
+func PriorityScheduler(s priority.Scheduler) Option {
+	return func(c *Config) error {
+		return maybeSetScheduler(c, s)
+	}
+}
+
+func maybeSetScheduler(c *Config, s priority.Scheduler) error {
+	if c.Scheduler == nil {
+		c.Scheduler = s
+		return nil
+	}
+	return errors.New(ErrSchedulerAlreadySet)
+}
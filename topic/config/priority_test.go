@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/priority"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerConflict(t *testing.T) {
+	as := assert.New(t)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.StrictPriority, config.Aging(time.Second),
+		), config.ErrSchedulerAlreadySet,
+	)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.WeightedFair(2, 1),
+			config.PriorityScheduler(priority.Strict),
+		), config.ErrSchedulerAlreadySet,
+	)
+}
+
+func TestSchedulerInvalid(t *testing.T) {
+	as := assert.New(t)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{}, config.WeightedFair(2, 0)),
+		config.ErrInvalidWeight,
+	)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{}, config.Aging(0)),
+		config.ErrInvalidAging,
+	)
+}
+
+func TestSchedulerDefault(t *testing.T) {
+	as := assert.New(t)
+	cfg := &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.Defaults))
+	as.NotNil(cfg.Scheduler)
+
+	top := essentials.NewPriorityTopic[any](2, config.WeightedFair(4))
+	as.Equal(2, len(top.Levels()))
+	top.Close()
+}
@@ -18,6 +18,13 @@ func Consumed(c *Config) error {
 	return maybeSetRetentionPolicy(c, policy)
 }
 
+// Compacted applies a compacted Policy to the Topic. Compaction also applies
+// when a compacted Policy is composed with others using RetentionPolicy
+func Compacted(c *Config) error {
+	policy := retention.MakeCompactedPolicy()
+	return maybeSetRetentionPolicy(c, policy)
+}
+
 // Counted applies a counted Policy to the Topic
 func Counted(c retention.Count) Option {
 	return func(t *Config) error {
@@ -32,6 +39,16 @@ func Permanent(c *Config) error {
 	return maybeSetRetentionPolicy(c, policy)
 }
 
+// Sized applies a sized Policy to the Topic, retaining the most recent
+// messages whose combined size, as measured by the Topic's Sizer, fits within
+// the specified number of bytes
+func Sized(s retention.Size) Option {
+	return func(t *Config) error {
+		policy := retention.MakeSizedPolicy(s)
+		return maybeSetRetentionPolicy(t, policy)
+	}
+}
+
 // Timed applies a timed Policy to the Topic
 func Timed(d time.Duration) Option {
 	return func(t *Config) error {
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/caravan/essentials/topic"
+)
+
+// Error messages
+const (
+	ErrSizerAlreadySet = "sizer already set in topic"
+)
+
+// Sizer applies a provided Sizer to the Topic, which is used to measure its
+// messages when applying byte bounds and size-based retention. If not
+// specified, topic.DefaultSizer is used when the Topic has a byte bound or a
+// retention Policy that includes a SizedPolicy. Otherwise, messages aren't
+// measured and the byte sizes reported to retention Policies are zero
+func Sizer(s topic.Sizer) Option {
+	return func(c *Config) error {
+		if c.Sizer != nil {
+			return errors.New(ErrSizerAlreadySet)
+		}
+		c.Sizer = s
+		return nil
+	}
+}
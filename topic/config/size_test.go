@@ -0,0 +1,64 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/retention"
+	"github.com/stretchr/testify/assert"
+)
+
+type sized []int
+
+func (s sized) Size() int {
+	return len(s) * 8
+}
+
+func TestSizerConflict(t *testing.T) {
+	as := assert.New(t)
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.Sizer(topic.DefaultSizer), config.Sizer(topic.DefaultSizer),
+		), config.ErrSizerAlreadySet,
+	)
+}
+
+func TestSizerDefault(t *testing.T) {
+	as := assert.New(t)
+	cfg := &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.MaxBytes(10), config.Defaults))
+	as.NotNil(cfg.Sizer)
+
+	as.Equal(5, cfg.Sizer("hello"))
+	as.Equal(3, cfg.Sizer([]byte{1, 2, 3}))
+	as.Equal(24, cfg.Sizer(sized{1, 2, 3}))
+	as.Equal(8, cfg.Sizer(int64(42)))
+	as.Equal(0, cfg.Sizer(nil))
+}
+
+func TestSizerUnused(t *testing.T) {
+	as := assert.New(t)
+	cfg := &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.Timed(time.Minute), config.Defaults))
+	as.Nil(cfg.Sizer)
+
+	cfg = &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.Defaults))
+	as.Nil(cfg.Sizer)
+}
+
+func TestSizerComposed(t *testing.T) {
+	as := assert.New(t)
+	cfg := &config.Config{}
+	as.Nil(config.ApplyOptions(cfg,
+		config.RetentionPolicy(retention.Not(retention.And(
+			retention.MakeTimedPolicy(time.Minute),
+			retention.MakeSizedPolicy(1024),
+		))),
+		config.Defaults,
+	))
+	as.NotNil(cfg.Sizer)
+}
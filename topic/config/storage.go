@@ -0,0 +1,58 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/caravan/essentials/topic/storage"
+)
+
+// Error messages
+const (
+	ErrStoragePathAlreadySet  = "storage path already set in topic"
+	ErrStorageCodecAlreadySet = "storage codec already set in topic"
+	ErrStorageSyncAlreadySet  = "storage sync policy already set in topic"
+)
+
+// Persistent configures a Topic to append its messages to segment files in
+// the specified directory. When the Topic is created, any segment files
+// already in that directory are used to restore its state. A Producer is
+// closed if a message that's sent to it can't be written
+func Persistent(path string) Option {
+	return func(c *Config) error {
+		if c.StoragePath != "" {
+			return errors.New(ErrStoragePathAlreadySet)
+		}
+		c.StoragePath = path
+		return nil
+	}
+}
+
+// StorageCodec applies the Codec used to persist a Topic's messages. If not
+// specified, messages are persisted using storage.JSON, which only restores
+// concrete message types faithfully. A Topic whose messages are, or contain,
+// interface values should be given a Codec that records their types
+func StorageCodec(codec storage.Codec) Option {
+	return func(c *Config) error {
+		if c.StorageCodec != nil {
+			return errors.New(ErrStorageCodecAlreadySet)
+		}
+		c.StorageCodec = codec
+		return nil
+	}
+}
+
+// StorageSync applies the SyncPolicy used to flush a Topic's segment files.
+// If not specified, segment files are flushed using storage.SyncSegment
+func StorageSync(s storage.SyncPolicy) Option {
+	return func(c *Config) error {
+		if c.StorageSync != 0 {
+			return errors.New(ErrStorageSyncAlreadySet)
+		}
+		if s < storage.SyncAlways || s > storage.SyncNever {
+			return fmt.Errorf(storage.ErrUnknownSyncPolicy, s)
+		}
+		c.StorageSync = s
+		return nil
+	}
+}
@@ -0,0 +1,62 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageConflict(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.Persistent(dir), config.Persistent(dir),
+		), config.ErrStoragePathAlreadySet,
+	)
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.StorageCodec(storage.JSON),
+			config.StorageCodec(storage.JSON),
+		), config.ErrStorageCodecAlreadySet,
+	)
+	as.EqualError(
+		config.ApplyOptions(&config.Config{},
+			config.StorageSync(storage.SyncNever),
+			config.StorageSync(storage.SyncAlways),
+		), config.ErrStorageSyncAlreadySet,
+	)
+	as.Error(
+		config.ApplyOptions(&config.Config{}, config.StorageSync(42)),
+	)
+}
+
+func TestStorageOptions(t *testing.T) {
+	as := assert.New(t)
+	top := essentials.NewTopic[any](
+		config.Persistent(t.TempDir()),
+		config.StorageCodec(storage.JSON),
+		config.StorageSync(storage.SyncNever),
+	)
+	as.NotNil(top)
+	top.Close()
+}
+
+func TestStorageOpen(t *testing.T) {
+	as := assert.New(t)
+	dir := t.TempDir()
+	top, err := essentials.OpenTopic[any](
+		config.Persistent(dir), config.Persistent(dir),
+	)
+	as.Nil(top)
+	as.EqualError(err, config.ErrStoragePathAlreadySet)
+
+	top, err = essentials.OpenTopic[any](config.Persistent(dir))
+	as.Nil(err)
+	as.NotNil(top)
+	top.Close()
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// Error messages
+const (
+	ErrTTLAlreadySet = "time-to-live already set in topic"
+	ErrInvalidTTL    = "time-to-live must be greater than zero"
+)
+
+// TTL applies a default time-to-live to the messages of a Topic. Messages
+// that have expired are skipped by Consumers, and are eventually removed.
+// Messages produced as Envelopes may specify their own expiration instead
+func TTL(d time.Duration) Option {
+	return func(c *Config) error {
+		if c.TTL != 0 {
+			return errors.New(ErrTTLAlreadySet)
+		}
+		if d <= 0 {
+			return errors.New(ErrInvalidTTL)
+		}
+		c.TTL = d
+		return nil
+	}
+}
@@ -0,0 +1,25 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/topic/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTTL(t *testing.T) {
+	as := assert.New(t)
+	cfg := &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.TTL(time.Minute)))
+	as.Equal(time.Minute, cfg.TTL)
+
+	as.EqualError(
+		config.ApplyOptions(cfg, config.TTL(time.Hour)),
+		config.ErrTTLAlreadySet,
+	)
+	as.EqualError(
+		config.ApplyOptions(&config.Config{}, config.TTL(0)),
+		config.ErrInvalidTTL,
+	)
+}
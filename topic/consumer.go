@@ -0,0 +1,58 @@
+package topic
+
+import (
+	"time"
+
+	"github.com/caravan/essentials/topic/backoff"
+)
+
+type (
+	// ConsumerConfig conveys the properties of a Consumer that one can
+	// configure using ConsumerOptions
+	ConsumerConfig struct {
+		Position          Position
+		Group             string
+		VisibilityTimeout time.Duration
+		Redelivery        backoff.Generator
+	}
+
+	// ConsumerOption applies an option to a Consumer configuration instance
+	ConsumerOption func(*ConsumerConfig)
+)
+
+// StartAt configures a Consumer to begin receiving messages at the specified
+// Position. By default, Consumers start at the Earliest Position
+func StartAt(p Position) ConsumerOption {
+	return func(c *ConsumerConfig) {
+		c.Position = p
+	}
+}
+
+// Group configures a Consumer to join the named consumer group. Members of
+// a group share a single position within the Topic, and each message is
+// delivered to exactly one of them. A group's starting Position is only
+// applied when the group is first created
+func Group(name string) ConsumerOption {
+	return func(c *ConsumerConfig) {
+		c.Group = name
+	}
+}
+
+// ApplyConsumerOptions applies ConsumerOptions to a Consumer configuration,
+// including any defaults that weren't explicitly set
+func ApplyConsumerOptions(o ...ConsumerOption) *ConsumerConfig {
+	res := &ConsumerConfig{}
+	for _, opt := range o {
+		opt(res)
+	}
+	if res.Position == nil {
+		res.Position = Earliest
+	}
+	if res.VisibilityTimeout == 0 {
+		res.VisibilityTimeout = DefaultVisibilityTimeout
+	}
+	if res.Redelivery == nil {
+		res.Redelivery = DefaultRedelivery
+	}
+	return res
+}
@@ -0,0 +1,64 @@
+package topic
+
+import (
+	"time"
+
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/message"
+)
+
+type (
+	// Headers are string values attached to a message by its producer,
+	// such as those used for tracing propagation
+	Headers map[string]string
+
+	// Envelope wraps a message along with the metadata attached to it
+	// when it was produced. When producing an Envelope, only its Message,
+	// Key, Headers, Timestamp, ExpiresAt, NotBefore and Delay are used. The
+	// remaining fields are assigned by the Topic. Headers must not be
+	// modified once they've been sent or received. If ExpiresAt is zero, the
+	// Topic's default time-to-live applies, and a received ExpiresAt is zero
+	// if the message never expires.
+	//
+	// A message produced with a NotBefore Time, or with a Delay from when
+	// it's produced, isn't delivered to Consumers until it's due. The
+	// messages that follow a delayed message aren't held back by it, so a
+	// delayed message is received after the messages that were produced
+	// before it became due. A received NotBefore is the Time at which the
+	// message became due, and a received Delay is always zero
+	Envelope[Msg any] struct {
+		Message   Msg
+		Key       Key
+		Headers   Headers
+		Timestamp time.Time
+		ExpiresAt time.Time
+		NotBefore time.Time
+		Delay     time.Duration
+
+		Offset     Offset
+		ProducedAt time.Time
+		ProducerID id.ID
+	}
+
+	// EnvelopeProducer exposes a way to push messages to its associated
+	// Topic along with their metadata
+	EnvelopeProducer[Msg any] interface {
+		message.ClosingSender[Envelope[Msg]]
+		Identified
+	}
+
+	// EnvelopeConsumer is a Consumer that receives each message of its
+	// associated Topic wrapped in an Envelope. Messages that weren't
+	// produced as Envelopes are received with only the metadata assigned
+	// by the Topic
+	EnvelopeConsumer[Msg any] interface {
+		message.ClosingReceiver[Envelope[Msg]]
+		Identified
+		Seeker
+	}
+)
+
+// Error messages
+const (
+	ErrEnvelopeGroupUnsupported = "envelope consumers can't join a group"
+)
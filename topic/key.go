@@ -0,0 +1,25 @@
+package topic
+
+type (
+	// Key identifies related messages, such as those that must be routed
+	// to the same partition. Keys are hashed and compared by their Bytes
+	Key interface {
+		Bytes() []byte
+	}
+
+	// StringKey is a Key based on a string
+	StringKey string
+
+	// Compactable is implemented by messages that can be compacted by a
+	// compacted Topic, which only retains the most recent message for each
+	// Key. A tombstone deletes its Key once it has been compacted itself
+	Compactable interface {
+		CompactionKey() Key
+		IsTombstone() bool
+	}
+)
+
+// Bytes returns the bytes of the StringKey
+func (k StringKey) Bytes() []byte {
+	return []byte(k)
+}
@@ -0,0 +1,30 @@
+package overflow
+
+// Strategy determines what happens when a message is put to a bounded Topic
+// that has no room left for it
+type Strategy uint8
+
+// Strategy values
+const (
+	_ Strategy = iota
+
+	// Block blocks the producing routine until the Topic's retention
+	// policy has discarded enough messages to make room
+	Block
+
+	// DropNewest silently discards the message being put, though a
+	// Producer's Produce methods report it with topic.ErrDropped
+	DropNewest
+
+	// DropOldest discards the oldest retained messages until there's room,
+	// regardless of the Topic's retention policy
+	DropOldest
+
+	// Fail rejects the message being put with an error
+	Fail
+)
+
+// Error messages
+const (
+	ErrUnknownStrategy = "unknown overflow strategy: %d"
+)
@@ -0,0 +1,35 @@
+package partition
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+
+	"github.com/caravan/essentials/topic"
+)
+
+// Partitioner selects the partition, from zero up to the specified number of
+// partitions, that a message with the provided Key is routed to
+type Partitioner func(key topic.Key, partitions int) int
+
+// Hash is a Partitioner that routes messages by hashing their Keys. Messages
+// with the same Key are always routed to the same partition. Messages without
+// a Key are all routed to the same partition as well, so a Topic that is
+// mostly produced to without Keys should use MakeRoundRobin instead
+func Hash(key topic.Key, partitions int) int {
+	h := fnv.New32a()
+	if key != nil {
+		_, _ = h.Write(key.Bytes())
+	}
+	return int(h.Sum32() % uint32(partitions))
+}
+
+// MakeRoundRobin returns a Partitioner that ignores Keys, routing messages to
+// each partition in turn. Messages with the same Key are not guaranteed to
+// be routed to the same partition
+func MakeRoundRobin() Partitioner {
+	var next uint64
+	return func(_ topic.Key, partitions int) int {
+		n := atomic.AddUint64(&next, 1) - 1
+		return int(n % uint64(partitions))
+	}
+}
@@ -0,0 +1,31 @@
+package partition_test
+
+import (
+	"testing"
+
+	"github.com/caravan/essentials/id"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/partition"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	as := assert.New(t)
+	k := topic.StringKey("some key")
+	p := partition.Hash(k, 8)
+	as.True(p >= 0 && p < 8)
+	as.Equal(p, partition.Hash(topic.StringKey("some key"), 8))
+
+	i := id.New()
+	as.Equal(partition.Hash(i, 5), partition.Hash(i, 5))
+	as.Equal(partition.Hash(nil, 3), partition.Hash(nil, 3))
+}
+
+func TestRoundRobin(t *testing.T) {
+	as := assert.New(t)
+	p := partition.MakeRoundRobin()
+	as.Equal(0, p(nil, 3))
+	as.Equal(1, p(topic.StringKey("ignored"), 3))
+	as.Equal(2, p(nil, 3))
+	as.Equal(0, p(nil, 3))
+}
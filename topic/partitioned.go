@@ -0,0 +1,51 @@
+package topic
+
+import (
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+)
+
+type (
+	// PartitionedTopic is a Topic that is split into a number of partitions,
+	// each of which is an independent Topic. Messages are routed to a
+	// partition based on their Keys, and ordering is only guaranteed for
+	// messages within the same partition
+	PartitionedTopic[Msg any] interface {
+		closer.Closer
+
+		// Length returns the combined virtual size of all partitions
+		Length() Length
+
+		// Partitions returns the partitions of this PartitionedTopic.
+		// Consumers can be attached to any individual partition
+		Partitions() []Topic[Msg]
+
+		// NewProducer returns a new KeyedProducer for this Topic
+		NewProducer() KeyedProducer[Msg]
+
+		// NewConsumer returns a new Consumer that receives messages from
+		// all partitions. Positions are resolved against each partition.
+		// Consumer groups must join individual partitions, so an error is
+		// returned if the Consumer is asked to join one here
+		NewConsumer(...ConsumerOption) (Consumer[Msg], error)
+	}
+
+	// Keyed pairs a message with the Key that is used to route it
+	Keyed[Msg any] struct {
+		Key     Key
+		Message Msg
+	}
+
+	// KeyedProducer exposes a way to push keyed messages to a
+	// PartitionedTopic
+	KeyedProducer[Msg any] interface {
+		message.ClosingSender[Keyed[Msg]]
+		Identified
+	}
+)
+
+// Error messages
+const (
+	ErrInvalidPartitionCount = "partition count must be at least one"
+	ErrPartitionedGroup      = "consumer groups must join individual partitions"
+)
@@ -0,0 +1,68 @@
+package topic
+
+import "time"
+
+type (
+	// Offset is a location within a Topic stream
+	Offset uint64
+
+	// Position resolves the Offset at which a Consumer should begin, or
+	// resume, receiving messages from its Topic
+	Position func(Locator) Offset
+
+	// Locator provides just enough information about a Topic's retained
+	// entries to be useful to a Position
+	Locator interface {
+		// Start returns the earliest Offset still retained by the Topic
+		Start() Offset
+
+		// End returns the Offset that the next message put to the Topic
+		// will be assigned
+		End() Offset
+
+		// Find returns the Offset of the first retained entry that was
+		// produced at or after the specified Time. If there is no such
+		// entry, the End Offset is returned
+		Find(time.Time) Offset
+	}
+
+	// Seeker is a resource that can be repositioned within its Topic
+	Seeker interface {
+		// Seek repositions the resource at the resolved Position. Any
+		// message that was pending delivery is abandoned
+		Seek(Position)
+	}
+)
+
+// Next returns the next logical Offset. Should Offsets ever become something
+// other than integers, this will spare consuming code
+func (o Offset) Next() Offset {
+	return o + 1
+}
+
+// Earliest is a Position that resolves to the earliest retained Offset
+func Earliest(l Locator) Offset {
+	return l.Start()
+}
+
+// Latest is a Position that resolves to the tail of the Topic, meaning only
+// messages put to the Topic after resolution will be received
+func Latest(l Locator) Offset {
+	return l.End()
+}
+
+// AtOffset returns a Position that resolves to the specified Offset. If that
+// Offset is no longer retained, the earliest retained Offset will be used
+func AtOffset(o Offset) Position {
+	return func(Locator) Offset {
+		return o
+	}
+}
+
+// AtTime returns a Position that resolves to the first retained entry that was
+// produced at or after the specified Time
+func AtTime(t time.Time) Position {
+	return func(l Locator) Offset {
+		return l.Find(t)
+	}
+}
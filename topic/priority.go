@@ -0,0 +1,59 @@
+package topic
+
+import (
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/message"
+)
+
+type (
+	// PriorityTopic is a Topic that is split into a number of priority
+	// levels, each of which is an independent Topic. Consumers receive
+	// messages from higher priority levels first, as directed by the
+	// Topic's priority Scheduler. Ordering is only guaranteed for messages
+	// of the same priority
+	PriorityTopic[Msg any] interface {
+		closer.Closer
+
+		// Length returns the combined virtual size of all levels
+		Length() Length
+
+		// Levels returns the priority levels of this PriorityTopic, from
+		// highest to lowest priority. Consumers can be attached to any
+		// individual level
+		Levels() []Topic[Msg]
+
+		// NewProducer returns a new PriorityProducer for this Topic
+		NewProducer() PriorityProducer[Msg]
+
+		// NewConsumer returns a new Consumer that receives messages from
+		// all levels. Positions are resolved against each level. Consumer
+		// groups must join individual levels, so an error is returned if
+		// the Consumer is asked to join one here
+		NewConsumer(...ConsumerOption) (Consumer[Msg], error)
+	}
+
+	// Priority identifies a level of a PriorityTopic. Priority zero is the
+	// highest, and each subsequent level is of a lower priority
+	Priority int
+
+	// Prioritized pairs a message with the Priority of the level that it
+	// is routed to
+	Prioritized[Msg any] struct {
+		Priority Priority
+		Message  Msg
+	}
+
+	// PriorityProducer exposes a way to push prioritized messages to a
+	// PriorityTopic
+	PriorityProducer[Msg any] interface {
+		message.ClosingSender[Prioritized[Msg]]
+		Identified
+	}
+)
+
+// Error messages
+const (
+	ErrInvalidPriorityLevels = "priority level count must be at least one"
+	ErrInvalidPriority       = "priority is not a level of the topic"
+	ErrPriorityGroup         = "consumer groups must join individual levels"
+)
@@ -0,0 +1,90 @@
+package priority
+
+import (
+	"time"
+
+	"github.com/caravan/essentials/topic"
+)
+
+type (
+	// Scheduler instantiates a Selector for each Consumer of a
+	// PriorityTopic, so that Selectors can keep track of what their
+	// Consumer has already delivered
+	Scheduler func() Selector
+
+	// Selector chooses which of the waiting Heads a Consumer delivers next,
+	// returning its index. Heads are never empty, and are ordered from
+	// highest to lowest priority. A Selector is only called again once the
+	// Head it chose has been delivered, or is no longer available
+	Selector func(now time.Time, heads []Head) int
+
+	// Head describes the message waiting at the front of a priority level
+	Head struct {
+		Priority   topic.Priority
+		ProducedAt time.Time
+	}
+)
+
+// Strict is a Scheduler that always delivers from the highest priority level
+// with a waiting message. Lower priority levels are starved for as long as
+// higher priority messages keep arriving
+func Strict() Selector {
+	return func(time.Time, []Head) int {
+		return 0
+	}
+}
+
+// MakeWeightedFair returns a Scheduler that shares deliveries among the levels
+// with waiting messages in proportion to their weights, interleaving them as
+// smoothly as possible. Weights are specified from highest to lowest priority,
+// and levels without a specified weight are given a weight of one
+func MakeWeightedFair(weights ...int) Scheduler {
+	return func() Selector {
+		var credits []int
+		return func(_ time.Time, heads []Head) int {
+			total := 0
+			res := 0
+			for i, h := range heads {
+				p := int(h.Priority)
+				for len(credits) <= p {
+					credits = append(credits, 0)
+				}
+				w := weightOf(weights, p)
+				credits[p] += w
+				total += w
+				if credits[p] > credits[heads[res].Priority] {
+					res = i
+				}
+			}
+			credits[heads[res].Priority] -= total
+			return res
+		}
+	}
+}
+
+func weightOf(weights []int, p int) int {
+	if p < len(weights) {
+		return weights[p]
+	}
+	return 1
+}
+
+// MakeAging returns a Scheduler that promotes a waiting message by one level
+// for every interval that it has waited since being produced, delivering the
+// message whose promoted level is highest. When levels tie, the message of
+// higher priority is delivered
+func MakeAging(interval time.Duration) Scheduler {
+	return func() Selector {
+		return func(now time.Time, heads []Head) int {
+			res := 0
+			best := 0
+			for i, h := range heads {
+				aged := int(now.Sub(h.ProducedAt) / interval)
+				if p := int(h.Priority) - aged; i == 0 || p < best {
+					res, best = i, p
+				}
+			}
+			return res
+		}
+	}
+}
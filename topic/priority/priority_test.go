@@ -0,0 +1,65 @@
+package priority_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/priority"
+	"github.com/stretchr/testify/assert"
+)
+
+func heads(levels ...topic.Priority) []priority.Head {
+	res := make([]priority.Head, len(levels))
+	for i, l := range levels {
+		res[i] = priority.Head{Priority: l}
+	}
+	return res
+}
+
+func TestStrict(t *testing.T) {
+	as := assert.New(t)
+	sel := priority.Strict()
+	as.Equal(0, sel(time.Now(), heads(0, 1, 2)))
+	as.Equal(0, sel(time.Now(), heads(2)))
+}
+
+func TestWeightedFair(t *testing.T) {
+	as := assert.New(t)
+	sel := priority.MakeWeightedFair(2, 1)()
+	var res []int
+	for i := 0; i < 6; i++ {
+		res = append(res, sel(time.Now(), heads(0, 1)))
+	}
+	as.Equal([]int{0, 1, 0, 0, 1, 0}, res)
+
+	// levels without waiting messages don't accumulate credit
+	sel = priority.MakeWeightedFair(4, 1)()
+	for i := 0; i < 3; i++ {
+		as.Equal(0, sel(time.Now(), heads(1)))
+	}
+	as.Equal(0, sel(time.Now(), heads(0, 1)))
+
+	// unweighted levels share evenly
+	sel = priority.MakeWeightedFair()()
+	as.Equal(0, sel(time.Now(), heads(1, 2)))
+	as.Equal(1, sel(time.Now(), heads(1, 2)))
+}
+
+func TestAging(t *testing.T) {
+	as := assert.New(t)
+	sel := priority.MakeAging(time.Second)()
+	now := time.Unix(100, 0)
+	h := []priority.Head{
+		{Priority: 0, ProducedAt: now},
+		{Priority: 2, ProducedAt: now.Add(-time.Second)},
+	}
+	as.Equal(0, sel(now, h))
+
+	// ties go to the higher priority level
+	h[1].ProducedAt = now.Add(-2 * time.Second)
+	as.Equal(0, sel(now, h))
+
+	h[1].ProducedAt = now.Add(-3 * time.Second)
+	as.Equal(1, sel(now, h))
+}
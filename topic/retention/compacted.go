@@ -0,0 +1,70 @@
+package retention
+
+type (
+	// CompactedPolicy describes a Policy that only retains the most recent
+	// message for each Key. Messages that have been superseded by a later
+	// message with the same Key are removed from the Topic, while the
+	// remaining messages keep their Offsets. Messages that don't implement
+	// topic.Compactable are never compacted. A segment of the Topic is only
+	// reclaimed once all of its messages have been removed and every segment
+	// before it has been reclaimed, so a long-lived message can hold on to
+	// the segments that follow it
+	CompactedPolicy interface {
+		Policy
+		Compactor
+
+		// RetainTombstone determines whether a tombstone that is no
+		// longer superseded should continue to be retained. Its Entries
+		// describe only the tombstone itself
+		RetainTombstone(*Statistics) bool
+	}
+
+	// Compactor is implemented by Policies that can compact a Topic,
+	// including Policies that are composed of a CompactedPolicy. Compaction
+	// returns the CompactedPolicy to apply, if there is one
+	Compactor interface {
+		Compaction() (CompactedPolicy, bool)
+	}
+
+	compactedPolicy struct{}
+)
+
+var _compactedPolicy = &compactedPolicy{}
+
+// MakeCompactedPolicy returns a Policy that only retains the most recent
+// message for each Key. Tombstones are retained until they've been consumed
+// by all active Consumers
+func MakeCompactedPolicy() CompactedPolicy {
+	return _compactedPolicy
+}
+
+func (p *compactedPolicy) Compaction() (CompactedPolicy, bool) {
+	return p, true
+}
+
+func (*compactedPolicy) InitialState() State {
+	return nil
+}
+
+func (*compactedPolicy) Retain(s State, _ *Statistics) (State, bool) {
+	// segments are only discarded once they've been compacted away
+	return s, true
+}
+
+func (*compactedPolicy) RetainTombstone(r *Statistics) bool {
+	for _, o := range r.Log.CursorOffsets {
+		if o <= r.Entries.LastOffset {
+			return true
+		}
+	}
+	return false
+}
+
+// Compaction returns the CompactedPolicy that the specified Policy is, or is
+// composed of, if there is one
+func Compaction(p Policy) (CompactedPolicy, bool) {
+	if c, ok := p.(Compactor); ok {
+		return c.Compaction()
+	}
+	return nil, false
+}
@@ -0,0 +1,72 @@
+package retention_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/retention"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactedPolicy(t *testing.T) {
+	as := assert.New(t)
+	p := retention.MakeCompactedPolicy()
+	as.NotNil(p)
+	as.Nil(p.InitialState())
+
+	_, ok := p.Retain(nil, nil)
+	as.True(ok)
+}
+
+func TestCompaction(t *testing.T) {
+	as := assert.New(t)
+	compacted := retention.MakeCompactedPolicy()
+	timed := retention.MakeTimedPolicy(time.Hour)
+
+	p, ok := retention.Compaction(compacted)
+	as.True(ok)
+	as.Equal(compacted, p)
+
+	p, ok = retention.Compaction(retention.Not(retention.And(timed, compacted)))
+	as.True(ok)
+	as.Equal(compacted, p)
+
+	p, ok = retention.Compaction(retention.Or(timed, timed))
+	as.False(ok)
+	as.Nil(p)
+}
+
+func TestCompactedTombstones(t *testing.T) {
+	as := assert.New(t)
+	p := retention.MakeCompactedPolicy()
+
+	stats := func(offsets ...retention.Offset) *retention.Statistics {
+		return &retention.Statistics{
+			Log: &retention.LogStatistics{
+				CursorOffsets: offsets,
+			},
+			Entries: &retention.EntriesStatistics{
+				FirstOffset: 10,
+				LastOffset:  10,
+			},
+		}
+	}
+	as.False(p.RetainTombstone(stats()))
+	as.False(p.RetainTombstone(stats(11, 20)))
+	as.True(p.RetainTombstone(stats(20, 10)))
+	as.True(p.RetainTombstone(stats(3)))
+}
+
+func TestCompacted(t *testing.T) {
+	as := assert.New(t)
+	top := essentials.NewTopic[any](config.Compacted)
+	p := top.NewProducer()
+	c := top.NewConsumer()
+	p.Send() <- "not compactable"
+	as.Equal("not compactable", <-c.Receive())
+	p.Close()
+	c.Close()
+	top.Close()
+}
@@ -83,6 +83,10 @@ func (p *unaryPolicy) Policy() Policy {
 	return p.policy
 }
 
+func (p *unaryPolicy) Compaction() (CompactedPolicy, bool) {
+	return Compaction(p.policy)
+}
+
 // And returns a Policy from which both Policies must request that messages be
 // retained in order to do so
 func And(left Policy, right Policy) AndPolicy {
@@ -130,6 +134,13 @@ func (p *binaryPolicy) Right() Policy {
 	return p.right
 }
 
+func (p *binaryPolicy) Compaction() (CompactedPolicy, bool) {
+	if c, ok := Compaction(p.left); ok {
+		return c, true
+	}
+	return Compaction(p.right)
+}
+
 func (p *binaryPolicy) retain(s State, r *Statistics) (State, bool, bool) {
 	var lok, rok bool
 	state := s.(*binaryPolicyState)
@@ -25,9 +25,14 @@ type (
 		Entries     *EntriesStatistics
 	}
 
-	// LogStatistics provides Retention information about the Log
+	// LogStatistics provides Retention information about the Log. Expired
+	// is the number of entries that the Log has removed because their
+	// time-to-live had passed, plus the unacknowledged messages that
+	// AckConsumers stopped delivering again for the same reason
 	LogStatistics struct {
 		Length        topic.Length
+		Bytes         Size
+		Expired       Count
 		CursorOffsets []Offset
 	}
 
@@ -38,14 +43,9 @@ type (
 		LastOffset     Offset
 		FirstTimestamp time.Time
 		LastTimestamp  time.Time
+		Bytes          Size
 	}
 
 	// Offset is a location within a Topic stream
-	Offset uint64
+	Offset = topic.Offset
 )
-
-// Next returns the next logical Offset. Should Offsets ever become something
-// other than integers, this will spare consuming code
-func (o Offset) Next() Offset {
-	return o + 1
-}
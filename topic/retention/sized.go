@@ -0,0 +1,39 @@
+package retention
+
+type (
+	// SizedPolicy describes a Policy that only retains the most recent
+	// messages whose combined size fits within the specified number of bytes
+	SizedPolicy interface {
+		Policy
+		Size() Size
+	}
+
+	// Size is the retained size of a Topic stream in bytes
+	Size uint64
+
+	sizedPolicy struct {
+		size Size
+	}
+)
+
+// MakeSizedPolicy returns a Policy that only retains the most recent messages
+// whose combined size fits within the specified number of bytes. A range of
+// entries is discarded once the entries that follow it are enough to fill
+// that size
+func MakeSizedPolicy(s Size) SizedPolicy {
+	return &sizedPolicy{
+		size: s,
+	}
+}
+
+func (p *sizedPolicy) Size() Size {
+	return p.size
+}
+
+func (*sizedPolicy) InitialState() State {
+	return nil
+}
+
+func (p *sizedPolicy) Retain(s State, r *Statistics) (State, bool) {
+	return s, r.Log.Bytes-r.Entries.Bytes < p.size
+}
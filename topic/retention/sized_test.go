@@ -0,0 +1,36 @@
+package retention_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/essentials/topic/retention"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizedPolicy(t *testing.T) {
+	as := assert.New(t)
+	p := retention.MakeSizedPolicy(1024)
+	as.NotNil(p)
+	as.Equal(retention.Size(1024), p.Size())
+}
+
+func TestSized(t *testing.T) {
+	as := assert.New(t)
+	top := essentials.NewTopic[string](config.Sized(1000))
+
+	p := top.NewProducer()
+	for i := 0; i < 256; i++ {
+		p.Send() <- fmt.Sprintf("%010d", i) // 10 bytes each
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	c := top.NewConsumer()
+	as.Equal(fmt.Sprintf("%010d", 128), <-c.Receive())
+
+	p.Close()
+	c.Close()
+}
@@ -0,0 +1,34 @@
+package topic
+
+import "reflect"
+
+type (
+	// Sized is implemented by messages that can report their size in bytes,
+	// for the purpose of bounding or retaining a Topic by the number of
+	// bytes it holds
+	Sized interface {
+		Size() int
+	}
+
+	// Sizer returns the size in bytes of a message
+	Sizer func(msg any) int
+)
+
+// DefaultSizer is the Sizer used by a Topic unless another is configured.
+// Messages that implement Sized report their own size, strings and byte
+// slices are measured by their length, and anything else by the shallow size
+// of its type
+func DefaultSizer(msg any) int {
+	switch m := msg.(type) {
+	case Sized:
+		return m.Size()
+	case string:
+		return len(m)
+	case []byte:
+		return len(m)
+	case nil:
+		return 0
+	default:
+		return int(reflect.TypeOf(m).Size())
+	}
+}
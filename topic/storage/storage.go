@@ -0,0 +1,56 @@
+package storage
+
+import "encoding/json"
+
+type (
+	// Codec marshals messages to, and unmarshals messages from, the bytes
+	// that are persisted in a Topic's segment files
+	Codec interface {
+		Marshal(any) ([]byte, error)
+		Unmarshal([]byte, any) error
+	}
+
+	// SyncPolicy determines how often persisted segment files are flushed
+	// to stable storage
+	SyncPolicy uint8
+
+	jsonCodec struct{}
+)
+
+// SyncPolicy values
+const (
+	_ SyncPolicy = iota
+
+	// SyncAlways flushes a segment file after every message is written
+	SyncAlways
+
+	// SyncSegment flushes a segment file when it has been filled and the
+	// next segment file is started, and when the Topic is closed
+	SyncSegment
+
+	// SyncNever leaves flushing segment files to the operating system
+	SyncNever
+)
+
+// Error messages
+const (
+	ErrCorruptSegment    = "segment file is corrupt: %s"
+	ErrSegmentGap        = "segment file does not follow its predecessor: %s"
+	ErrWriteFailed       = "failed to write to segment file: %w"
+	ErrUnmarshalFailed   = "failed to unmarshal message in segment file %s: %w"
+	ErrUnknownSyncPolicy = "unknown sync policy: %d"
+)
+
+// JSON is a Codec that persists messages using encoding/json. Because JSON
+// doesn't record Go types, a message that is, or contains, an interface value
+// is restored with encoding/json's generic types. For example, a Topic of any
+// that persisted an int restores it as a float64
+var JSON Codec = jsonCodec{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
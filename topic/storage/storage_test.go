@@ -0,0 +1,24 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/caravan/essentials/topic/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonMessage struct {
+	Name  string
+	Count int
+}
+
+func TestJSONCodec(t *testing.T) {
+	as := assert.New(t)
+	data, err := storage.JSON.Marshal(&jsonMessage{"hello", 42})
+	as.Nil(err)
+
+	var res jsonMessage
+	as.Nil(storage.JSON.Unmarshal(data, &res))
+	as.Equal(jsonMessage{"hello", 42}, res)
+	as.Error(storage.JSON.Unmarshal([]byte("{"), &res))
+}
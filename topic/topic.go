@@ -1,6 +1,11 @@
 package topic
 
 import (
+	"errors"
+	"iter"
+	"time"
+
+	"github.com/caravan/essentials/closer"
 	"github.com/caravan/essentials/id"
 	"github.com/caravan/essentials/message"
 )
@@ -10,8 +15,16 @@ type (
 	Length uint64
 
 	// Topic is where you put your stuff. They are implemented as a
-	// first-in-first-out (FIFO) Log.
+	// first-in-first-out (FIFO) Log, but a delayed message is delivered
+	// once it's due rather than in the order it was appended, so it may
+	// follow messages that were appended after it. Closing a Topic closes
+	// its Producers and allows its Consumers to drain whatever messages are
+	// retained. Delayed messages that aren't due by the time a Consumer has
+	// drained the rest aren't delivered to it, though a persistent Topic
+	// keeps them for when it's reopened
 	Topic[Msg any] interface {
+		closer.Closer
+
 		// Length returns the current virtual size of the Topic
 		Length() Length
 
@@ -19,7 +32,24 @@ type (
 		NewProducer() Producer[Msg]
 
 		// NewConsumer returns a new Consumer for this Topic
-		NewConsumer() Consumer[Msg]
+		NewConsumer(...ConsumerOption) Consumer[Msg]
+
+		// NewAckConsumer returns a new AckConsumer for this Topic. The
+		// members of a consumer group share the redelivery of any
+		// messages that aren't acknowledged
+		NewAckConsumer(...ConsumerOption) AckConsumer[Msg]
+
+		// NewEnvelopeProducer returns a new EnvelopeProducer for this Topic
+		NewEnvelopeProducer() EnvelopeProducer[Msg]
+
+		// NewEnvelopeConsumer returns a new EnvelopeConsumer for this
+		// Topic. EnvelopeConsumers can't join a consumer group, and an
+		// error is returned if one is asked to
+		NewEnvelopeConsumer(...ConsumerOption) (EnvelopeConsumer[Msg], error)
+
+		// Snapshot returns an iterator over the messages that are retained
+		// by the Topic when iteration begins, along with their Offsets
+		Snapshot() iter.Seq2[Offset, Msg]
 	}
 
 	// Identified is any resource that can be uniquely identified
@@ -30,18 +60,39 @@ type (
 
 	// Producer exposes a way to push messages to its associated Topic.
 	// messages pushed to the Topic are capable of being independently
-	// received by all Consumers
+	// received by all Consumers. If a message sent to the Producer can't be
+	// written to a persistent Topic's storage, the Producer is closed and
+	// its subsequent calls to Produce return that error
 	Producer[Msg any] interface {
 		message.ClosingSender[Msg]
 		Identified
+
+		// Produce appends a message to the Topic, returning the Offset
+		// that it was assigned once it has been appended. Messages sent
+		// and produced by the same Producer are appended in order
+		Produce(Msg) (Offset, error)
+
+		// ProduceBatch appends messages to the Topic in order, returning
+		// the Offsets they were assigned. If an error occurs, the Offsets
+		// of the messages appended before it are returned along with it
+		ProduceBatch([]Msg) ([]Offset, error)
 	}
 
 	// Consumer exposes a way to receive messages from its associated Topic.
 	// Each Consumer created independently tracks its own position within
-	// the Topic
+	// the Topic, unless it is a member of a consumer group, in which case
+	// that position is shared by the group. The position can be moved
+	// using Seek
 	Consumer[Msg any] interface {
 		message.ClosingReceiver[Msg]
 		Identified
+		Seeker
+
+		// ReceiveBatch returns up to max messages that are available,
+		// waiting up to the specified Duration for at least one of them to
+		// arrive. False is returned if the Consumer is closed before any
+		// messages could be received
+		ReceiveBatch(max int, wait time.Duration) ([]Msg, bool)
 	}
 )
 
@@ -49,4 +100,13 @@ type (
 const (
 	ErrConsumerNotClosed = "consumer finalized without being closed: %s"
 	ErrProducerNotClosed = "producer finalized without being closed: %s"
+	ErrTopicFull         = "topic is full"
+	ErrTopicClosed       = "topic is closed"
+	ErrProducerClosed    = "producer is closed"
+	ErrMessageDropped    = "message was dropped by a full topic"
 )
+
+// ErrDropped is returned by a Producer's Produce methods when a full Topic's
+// overflow Strategy silently discards the message. Messages that are sent to
+// a Producer's channel are dropped without an error
+var ErrDropped = errors.New(ErrMessageDropped)